@@ -0,0 +1,112 @@
+package bug
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EventType is something happening to a ticket that can trigger an automatic
+// workflow transition
+type EventType string
+
+const (
+	// ReviewAcceptedEvent is raised when a review of the ticket is stored with
+	// the overall status accepted
+	ReviewAcceptedEvent EventType = "review-accepted"
+	// ChecklistsPassedEvent is raised when a checklist is stored and all the
+	// checklists of the ticket are PASSED or NA
+	ChecklistsPassedEvent EventType = "checklists-passed"
+	// CommitMergedEvent is raised when a commit referencing the ticket lands
+	// on the main branch
+	CommitMergedEvent EventType = "commit-merged"
+)
+
+// AutomationMetadataKey is the metadata set on the operations made by automatic
+// transitions, its value is the event that triggered them
+const AutomationMetadataKey = "automation"
+
+// ticketReferenceRegexp matches ticket ids in commit messages, either as
+// inserted by the prepare-commit-msg hook ("#1a2b3c4" or ":1a2b3c4") or as a
+// "Ticket: 1a2b3c4" trailer
+var ticketReferenceRegexp = regexp.MustCompile(`(?m)(?:(?:^|[\s(\[])[#:]|^Ticket:[ \t]*)([0-9a-f]{7,64})\b`)
+
+// Validate checks the event type is known
+func (e EventType) Validate() error {
+	switch e {
+	case ReviewAcceptedEvent, ChecklistsPassedEvent, CommitMergedEvent:
+		return nil
+	default:
+		return fmt.Errorf("unknown event %q", e)
+	}
+}
+
+// Trigger returns the event making the transition automatically, if any
+func (t *Transition) Trigger() EventType {
+	return t.trigger
+}
+
+// validateTriggers checks the automatic transitions triggered by an event
+// can't loop back to the state they started from, which would make the ticket
+// go round for as long as the event is fired
+func (w *Workflow) validateTriggers() error {
+	for _, t := range w.transitions {
+		if t.trigger == "" {
+			continue
+		}
+
+		path := []string{string(t.start)}
+		next := &t
+		for i := 0; next != nil && i < len(w.transitions); i++ {
+			path = append(path, string(next.end))
+			if next.end == t.start {
+				return fmt.Errorf("transitions triggered by %s loop: %s", t.trigger, strings.Join(path, " -> "))
+			}
+			next = w.AutomaticTransition(next.end, t.trigger)
+		}
+	}
+	return nil
+}
+
+// AutomaticTransition returns the transition from the given state triggered by
+// the event, or nil if there isn't one
+func (w *Workflow) AutomaticTransition(from Status, event EventType) *Transition {
+	for i := range w.transitions {
+		if w.transitions[i].start == from && w.transitions[i].trigger == event {
+			return &w.transitions[i]
+		}
+	}
+	return nil
+}
+
+// AutomaticTransition returns the status the event moves the ticket to, if the
+// workflow of the ticket has an automatic transition for it
+func (snap *Snapshot) AutomaticTransition(configs *ConfigCache, event EventType) (Status, bool) {
+	w, err := snap.Workflow(configs)
+	if err != nil {
+		return "", false
+	}
+
+	t := w.AutomaticTransition(snap.Status, event)
+	if t == nil {
+		return "", false
+	}
+
+	return t.end, true
+}
+
+// TicketReferences returns the ticket ids, or id prefixes, referenced in the
+// text, without duplicates
+func TicketReferences(text string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+
+	for _, match := range ticketReferenceRegexp.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			refs = append(refs, match[1])
+		}
+	}
+
+	return refs
+}
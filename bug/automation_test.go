@@ -0,0 +1,84 @@
+package bug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutomation_TicketReferences(t *testing.T) {
+	message := `#1a2b3c4 fix the frobnicator
+
+Also touches [:5d6e7f8a] and #1a2b3c4 again, but not abc#1234567 or #xyz1234.
+
+Ticket: 9f8e7d6c5b
+`
+	assert.Equal(t, []string{"1a2b3c4", "5d6e7f8a", "9f8e7d6c5b"}, TicketReferences(message))
+	assert.Empty(t, TicketReferences("no reference, #123 is too short"))
+}
+
+func TestAutomation_AutomaticTransition(t *testing.T) {
+	data := `{
+		"workflow:test": {
+			"initialState": "inreview",
+			"transitions": [
+				{"start": "inreview", "end": "inprogress"},
+				{"start": "inreview", "end": "reviewed", "trigger": "review-accepted"},
+				{"start": "reviewed", "end": "accepted", "trigger": "checklists-passed"},
+				{"start": "accepted", "end": "merged", "trigger": "commit-merged"}
+			]
+		}
+	}`
+
+	workflows, err := ParseWorkflows([]byte(data))
+	assert.NoError(t, err)
+	wf := &workflows[0]
+
+	assert.Nil(t, wf.AutomaticTransition(InReviewStatus, ChecklistsPassedEvent))
+	if tr := wf.AutomaticTransition(InReviewStatus, ReviewAcceptedEvent); assert.NotNil(t, tr) {
+		assert.Equal(t, ReviewedStatus, tr.End())
+	}
+	if tr := wf.AutomaticTransition(AcceptedStatus, CommitMergedEvent); assert.NotNil(t, tr) {
+		assert.Equal(t, MergedStatus, tr.End())
+	}
+
+	var invalidConfigs = []string{
+		// unknown event
+		`{"workflow:test": {"initialState": "proposed", "transitions": [
+			{"start": "proposed", "end": "vetted", "trigger": "sunrise"}]}}`,
+		// two transitions triggered by the same event from the same state
+		`{"workflow:test": {"initialState": "proposed", "transitions": [
+			{"start": "proposed", "end": "vetted", "trigger": "commit-merged"},
+			{"start": "proposed", "end": "merged", "trigger": "commit-merged"}]}}`,
+		// transitions triggered by the same event going round
+		`{"workflow:test": {"initialState": "proposed", "transitions": [
+			{"start": "proposed", "end": "vetted", "trigger": "review-accepted"},
+			{"start": "vetted", "end": "proposed", "trigger": "review-accepted"}]}}`,
+		`{"workflow:test": {"initialState": "proposed", "transitions": [
+			{"start": "proposed", "end": "vetted", "trigger": "commit-merged"},
+			{"start": "vetted", "end": "inprogress", "trigger": "commit-merged"},
+			{"start": "inprogress", "end": "vetted", "trigger": "commit-merged"}]}}`,
+		`{"workflow:test": {"initialState": "proposed", "transitions": [
+			{"start": "proposed", "end": "proposed", "trigger": "commit-merged"}]}}`,
+	}
+
+	for _, c := range invalidConfigs {
+		_, err := ParseWorkflows([]byte(c))
+		assert.Error(t, err, c)
+	}
+
+	// Automatic transitions can still follow each other, or go back on
+	// another event
+	_, err = ParseWorkflows([]byte(`{"workflow:test": {"initialState": "inreview", "transitions": [
+		{"start": "inreview", "end": "reviewed", "trigger": "review-accepted"},
+		{"start": "reviewed", "end": "accepted", "trigger": "review-accepted"},
+		{"start": "accepted", "end": "inreview", "trigger": "commit-merged"}]}}`))
+	assert.NoError(t, err)
+
+	_, err = ParseWorkflows([]byte(`{"workflow:test": {"initialState": "proposed", "transitions": [
+		{"start": "proposed", "end": "vetted", "trigger": "review-accepted"},
+		{"start": "vetted", "end": "proposed", "trigger": "review-accepted"}]}}`))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "proposed -> vetted -> proposed")
+	}
+}
@@ -65,6 +65,9 @@ type Bug struct {
 	// a temporary pack of operations used for convenience to pile up new operations
 	// before a commit
 	staging OperationPack
+
+	// the operations the last merge added to the local history
+	merged []Operation
 }
 
 // NewBug create a new Bug
@@ -629,12 +632,15 @@ func (bug *Bug) Merge(repo repository.Repo, other Interface) (bool, error) {
 		return false, nil
 	}
 
+	var merged []Operation
+
 	// get other bug's extra packs
 	for i := ancestorIndex + 1; i < len(otherBug.packs); i++ {
 		// clone is probably not necessary
 		newPack := otherBug.packs[i].Clone()
 
 		newPacks = append(newPacks, newPack)
+		merged = append(merged, newPack.Operations...)
 		bug.lastCommit = newPack.commitHash
 	}
 
@@ -666,6 +672,7 @@ func (bug *Bug) Merge(repo repository.Repo, other Interface) (bool, error) {
 	}
 
 	bug.packs = newPacks
+	bug.merged = merged
 
 	// Update the git ref
 	err = repo.UpdateRef(bugsRefPattern+bug.id.String(), bug.lastCommit)
@@ -676,6 +683,12 @@ func (bug *Bug) Merge(repo repository.Repo, other Interface) (bool, error) {
 	return true, nil
 }
 
+// MergedOperations returns the operations the last merge added to the local
+// history of the bug, all of them if the bug wasn't known locally
+func (bug *Bug) MergedOperations() []Operation {
+	return bug.merged
+}
+
 // Id return the Bug identifier
 func (bug *Bug) Id() entity.Id {
 	if bug.id == "" {
@@ -732,6 +745,20 @@ func (bug *Bug) LastOp() Operation {
 	return lastPack.Operations[len(lastPack.Operations)-1]
 }
 
+// OperationCommits returns the hash of the git commit storing each committed
+// operation, by operation id. Staged operations are not included.
+func (bug *Bug) OperationCommits() map[entity.Id]repository.Hash {
+	result := make(map[entity.Id]repository.Hash)
+
+	for _, pack := range bug.packs {
+		for _, op := range pack.Operations {
+			result[op.Id()] = pack.commitHash
+		}
+	}
+
+	return result
+}
+
 // Compile a bug in a easily usable snapshot
 func (bug *Bug) Compile() Snapshot {
 	snap := Snapshot{
@@ -103,6 +103,10 @@ func MergeAll(repo repository.ClockedRepo, remote string) <-chan entity.MergeRes
 					return
 				}
 
+				for _, pack := range remoteBug.packs {
+					remoteBug.merged = append(remoteBug.merged, pack.Operations...)
+				}
+
 				out <- entity.NewMergeStatus(entity.MergeStatusNew, id, remoteBug)
 				continue
 			}
@@ -177,3 +177,33 @@ func TestBugRemove(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, ids, 100)
 }
+
+func TestBugOperationCommits(t *testing.T) {
+	repo := repository.NewMockRepoForTest()
+	bug1 := NewBug()
+
+	rene := identity.NewIdentity("René Descartes", "rene@descartes.fr")
+	createOp := NewCreateOp(rene, time.Now().Unix(), "title", "message", nil)
+	setTitleOp := NewSetTitleOp(rene, time.Now().Unix(), "title2", "title1")
+	addCommentOp := NewAddCommentOp(rene, time.Now().Unix(), "message2", nil)
+
+	bug1.Append(createOp)
+	bug1.Append(setTitleOp)
+	require.NoError(t, bug1.Commit(repo))
+	first := bug1.lastCommit
+
+	bug1.Append(addCommentOp)
+
+	// Staged operations have no commit yet
+	commits := bug1.OperationCommits()
+	assert.Len(t, commits, 2)
+	assert.Equal(t, first, commits[createOp.Id()])
+	assert.Equal(t, first, commits[setTitleOp.Id()])
+
+	require.NoError(t, bug1.Commit(repo))
+
+	commits = bug1.OperationCommits()
+	assert.Len(t, commits, 3)
+	assert.Equal(t, bug1.lastCommit, commits[addCommentOp.Id()])
+	assert.NotEqual(t, first, bug1.lastCommit)
+}
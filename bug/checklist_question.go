@@ -0,0 +1,199 @@
+package bug
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QuestionKind is the kind of answer a checklist question expects in its Value,
+// on top of its state
+type QuestionKind string
+
+const (
+	// PlainQuestion only has a state and a comment
+	PlainQuestion QuestionKind = ""
+	// YesNoQuestion is answered with "yes" or "no"
+	YesNoQuestion QuestionKind = "yesno"
+	// NumberQuestion is answered with a number, optionally between Min and Max
+	NumberQuestion QuestionKind = "number"
+	// TextQuestion is answered with free text
+	TextQuestion QuestionKind = "text"
+	// ChoiceQuestion is answered with one of its Choices
+	ChoiceQuestion QuestionKind = "choice"
+)
+
+// Validate checks the kind is known
+func (k QuestionKind) Validate() error {
+	switch k {
+	case PlainQuestion, YesNoQuestion, NumberQuestion, TextQuestion, ChoiceQuestion:
+		return nil
+	default:
+		return fmt.Errorf("unknown question kind %q", k)
+	}
+}
+
+// Hint describes the answer expected by a question
+func (q ChecklistQuestion) Hint() string {
+	switch q.Kind {
+	case YesNoQuestion:
+		return "yes/no"
+	case NumberQuestion:
+		switch {
+		case q.Min != nil && q.Max != nil:
+			return fmt.Sprintf("number %g..%g", *q.Min, *q.Max)
+		case q.Min != nil:
+			return fmt.Sprintf("number >= %g", *q.Min)
+		case q.Max != nil:
+			return fmt.Sprintf("number <= %g", *q.Max)
+		default:
+			return "number"
+		}
+	case TextQuestion:
+		return "text"
+	case ChoiceQuestion:
+		return "one of " + strings.Join(q.Choices, ", ")
+	default:
+		return ""
+	}
+}
+
+// Validate checks the checklist template is well formed and the answers to its
+// questions follow it
+func (c Checklist) Validate() error {
+	if err := c.validateTemplate(); err != nil {
+		return err
+	}
+
+	for sn, s := range c.Sections {
+		for qn, q := range s.Questions {
+			if err := q.validateAnswer(c.CommentRequired); err != nil {
+				return fmt.Errorf("question %d.%d: %s", sn+1, qn+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTemplate checks the checklist template is well formed, regardless of
+// the answers
+func (c Checklist) validateTemplate() error {
+	if err := validateCommentRules(c.CommentRequired); err != nil {
+		return err
+	}
+
+	for sn, s := range c.Sections {
+		for qn, q := range s.Questions {
+			if err := q.validateTemplate(); err != nil {
+				return fmt.Errorf("question %d.%d: %s", sn+1, qn+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// CommentRequiredFor returns true if a comment must be given when the question
+// is in the given state, according to its rules and the ones of the checklist
+func (q ChecklistQuestion) CommentRequiredFor(checklistRules []string, state ChecklistState) bool {
+	for _, rules := range [][]string{checklistRules, q.CommentRequired} {
+		for _, r := range rules {
+			if s, err := StateFromString(r); err == nil && s == state {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateAnswer checks the state, answer and comment of the question
+func (q ChecklistQuestion) validateAnswer(checklistRules []string) error {
+	if err := q.State.Validate(); err != nil {
+		return fmt.Errorf("state: %s", err)
+	}
+	if err := q.validateValue(); err != nil {
+		return err
+	}
+
+	if q.CommentRequiredFor(checklistRules, q.State) && strings.TrimSpace(q.Comment) == "" {
+		return fmt.Errorf("a comment is required when the state is %s", q.State)
+	}
+
+	return nil
+}
+
+// validateTemplate checks the definition of the question
+func (q ChecklistQuestion) validateTemplate() error {
+	if err := q.Kind.Validate(); err != nil {
+		return err
+	}
+	if err := validateCommentRules(q.CommentRequired); err != nil {
+		return err
+	}
+
+	if q.Kind != NumberQuestion && (q.Min != nil || q.Max != nil) {
+		return fmt.Errorf("only number questions can have a range")
+	}
+	if q.Min != nil && q.Max != nil && *q.Min > *q.Max {
+		return fmt.Errorf("invalid range %g..%g", *q.Min, *q.Max)
+	}
+
+	if q.Kind == ChoiceQuestion && len(q.Choices) == 0 {
+		return fmt.Errorf("choice questions need choices")
+	}
+	if q.Kind != ChoiceQuestion && len(q.Choices) > 0 {
+		return fmt.Errorf("only choice questions can have choices")
+	}
+
+	return nil
+}
+
+// validateValue checks the answer matches the kind of the question. Typed
+// questions must be answered once they are PASSED or FAILED.
+func (q ChecklistQuestion) validateValue() error {
+	if q.Value == "" {
+		if q.Kind != PlainQuestion && (q.State == Passed || q.State == Failed) {
+			return fmt.Errorf("an answer (%s) is required when the state is %s", q.Hint(), q.State)
+		}
+		return nil
+	}
+
+	switch q.Kind {
+	case PlainQuestion:
+		return fmt.Errorf("the question doesn't take an answer")
+	case YesNoQuestion:
+		if q.Value != "yes" && q.Value != "no" {
+			return fmt.Errorf("invalid answer %q, expected yes or no", q.Value)
+		}
+	case NumberQuestion:
+		n, err := strconv.ParseFloat(q.Value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid answer %q, expected a number", q.Value)
+		}
+		if (q.Min != nil && n < *q.Min) || (q.Max != nil && n > *q.Max) {
+			return fmt.Errorf("answer %s out of range, expected %s", q.Value, q.Hint())
+		}
+	case ChoiceQuestion:
+		for _, c := range q.Choices {
+			if q.Value == c {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid answer %q, expected %s", q.Value, q.Hint())
+	}
+
+	return nil
+}
+
+func validateCommentRules(rules []string) error {
+	for _, r := range rules {
+		if strings.TrimSpace(r) == "" {
+			return fmt.Errorf("empty state in comment rule")
+		}
+		if _, err := StateFromString(r); err != nil {
+			return fmt.Errorf("invalid state %q in comment rule", r)
+		}
+	}
+	return nil
+}
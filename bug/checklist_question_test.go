@@ -0,0 +1,84 @@
+package bug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecklistQuestion_Validate(t *testing.T) {
+	min, max := 0.0, 100.0
+
+	checklist := func(q ChecklistQuestion) Checklist {
+		return Checklist{Label: "checklist:XYZ",
+			CommentRequired: []string{"FAILED", "NA"},
+			Sections:        []ChecklistSection{{Title: "ABC", Questions: []ChecklistQuestion{q}}},
+		}
+	}
+
+	var tests = []struct {
+		question ChecklistQuestion
+		valid    bool
+	}{
+		{ChecklistQuestion{Question: "1?"}, true},
+		{ChecklistQuestion{Question: "1?", State: Passed}, true},
+		{ChecklistQuestion{Question: "1?", State: Passed, Value: "yes"}, false},
+		{ChecklistQuestion{Question: "1?", Kind: "maybe"}, false},
+
+		// comment rules
+		{ChecklistQuestion{Question: "1?", State: NotApplicable}, false},
+		{ChecklistQuestion{Question: "1?", State: NotApplicable, Comment: " \n"}, false},
+		{ChecklistQuestion{Question: "1?", State: NotApplicable, Comment: "not relevant"}, true},
+		{ChecklistQuestion{Question: "1?", State: Failed, Comment: "broken"}, true},
+		{ChecklistQuestion{Question: "1?", State: Passed, CommentRequired: []string{"PASSED"}}, false},
+		{ChecklistQuestion{Question: "1?", CommentRequired: []string{"MAYBE"}}, false},
+
+		// yes/no
+		{ChecklistQuestion{Question: "1?", Kind: YesNoQuestion}, true},
+		{ChecklistQuestion{Question: "1?", Kind: YesNoQuestion, State: Passed}, false},
+		{ChecklistQuestion{Question: "1?", Kind: YesNoQuestion, State: Passed, Value: "no"}, true},
+		{ChecklistQuestion{Question: "1?", Kind: YesNoQuestion, State: Passed, Value: "maybe"}, false},
+
+		// number
+		{ChecklistQuestion{Question: "1?", Kind: NumberQuestion, Min: &min, Max: &max, State: Passed, Value: "42.5"}, true},
+		{ChecklistQuestion{Question: "1?", Kind: NumberQuestion, Min: &min, Max: &max, State: Passed, Value: "142"}, false},
+		{ChecklistQuestion{Question: "1?", Kind: NumberQuestion, Min: &min, State: Passed, Value: "-1"}, false},
+		{ChecklistQuestion{Question: "1?", Kind: NumberQuestion, State: Passed, Value: "lots"}, false},
+		{ChecklistQuestion{Question: "1?", Kind: NumberQuestion, Min: &max, Max: &min}, false},
+		{ChecklistQuestion{Question: "1?", Kind: TextQuestion, Min: &min}, false},
+
+		// text
+		{ChecklistQuestion{Question: "1?", Kind: TextQuestion, State: Passed, Value: "anything"}, true},
+
+		// choice
+		{ChecklistQuestion{Question: "1?", Kind: ChoiceQuestion, Choices: []string{"A", "B"}, State: Failed, Value: "B", Comment: "B"}, true},
+		{ChecklistQuestion{Question: "1?", Kind: ChoiceQuestion, Choices: []string{"A", "B"}, State: Passed, Value: "C"}, false},
+		{ChecklistQuestion{Question: "1?", Kind: ChoiceQuestion}, false},
+		{ChecklistQuestion{Question: "1?", Kind: YesNoQuestion, Choices: []string{"A", "B"}}, false},
+	}
+
+	for _, tc := range tests {
+		err := checklist(tc.question).Validate()
+		if tc.valid {
+			assert.NoError(t, err, tc.question)
+		} else {
+			assert.Error(t, err, tc.question)
+		}
+	}
+}
+
+func TestChecklistQuestion_ParseChecklists(t *testing.T) {
+	checklists, err := ParseChecklists([]byte(`{"checklist:XYZ": {"Label": "checklist:XYZ", "CommentRequired": ["NA"],
+		"Sections": [{"Title": "ABC", "Questions": [{"Question": "1?", "Kind": "number", "Min": 1, "Max": 5}]}]}}`))
+	assert.NoError(t, err)
+
+	q := checklists["checklist:XYZ"].Sections[0].Questions[0]
+	assert.Equal(t, NumberQuestion, q.Kind)
+	assert.Equal(t, "number 1..5", q.Hint())
+	assert.True(t, q.CommentRequiredFor(checklists["checklist:XYZ"].CommentRequired, NotApplicable))
+	assert.False(t, q.CommentRequiredFor(checklists["checklist:XYZ"].CommentRequired, Failed))
+
+	_, err = ParseChecklists([]byte(`{"checklist:XYZ": {"Label": "checklist:XYZ",
+		"Sections": [{"Title": "ABC", "Questions": [{"Question": "1?", "Kind": "choice"}]}]}}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package bug
+
+import (
+	"sort"
+
+	"github.com/daedaleanai/git-ticket/entity"
+)
+
+// ChecklistQuestionReport counts the answers a reviewer gave to a question of a
+// checklist across tickets
+type ChecklistQuestionReport struct {
+	Checklist Label
+	Section   string
+	Question  string
+	// Reviewer is empty for checklists nobody answered yet
+	Reviewer entity.Id
+
+	Passed        int
+	Failed        int
+	NotApplicable int
+	TBD           int
+
+	// The tickets where the question is FAILED or TBD
+	FailedTickets []entity.Id
+	TBDTickets    []entity.Id
+
+	// order is the position of the question when first seen
+	order int
+}
+
+type checklistReportKey struct {
+	checklist Label
+	section   string
+	question  string
+	reviewer  entity.Id
+}
+
+type checklistQuestionKey struct {
+	checklist Label
+	section   string
+	question  string
+}
+
+// ChecklistReport aggregates the answers to the checklists of several tickets,
+// per question and reviewer
+type ChecklistReport struct {
+	questions map[checklistReportKey]*ChecklistQuestionReport
+	order     map[checklistQuestionKey]int
+}
+
+// NewChecklistReport returns an empty report
+func NewChecklistReport() *ChecklistReport {
+	return &ChecklistReport{
+		questions: make(map[checklistReportKey]*ChecklistQuestionReport),
+		order:     make(map[checklistQuestionKey]int),
+	}
+}
+
+// Add counts the answers to the checklists of the snapshot. Only the checklists
+// with one of the given labels are counted, or all of them if none is given.
+// Checklists nobody answered are counted as TBD against their current template.
+func (r *ChecklistReport) Add(configs *ConfigCache, snap *Snapshot, only ...Label) {
+	for _, l := range snap.Labels {
+		if !l.IsChecklist() || (len(only) > 0 && !labelExist(only, l)) {
+			continue
+		}
+
+		answers := snap.Checklists[l]
+		if len(answers) == 0 {
+			template, err := configs.Checklist(l)
+			if err != nil {
+				continue
+			}
+			r.addChecklist(snap.Id(), "", template)
+			continue
+		}
+
+		reviewers := make([]entity.Id, 0, len(answers))
+		for reviewer := range answers {
+			reviewers = append(reviewers, reviewer)
+		}
+		sortIds(reviewers)
+
+		for _, reviewer := range reviewers {
+			r.addChecklist(snap.Id(), reviewer, answers[reviewer].Checklist)
+		}
+	}
+}
+
+func (r *ChecklistReport) addChecklist(ticket entity.Id, reviewer entity.Id, cl Checklist) {
+	for _, s := range cl.Sections {
+		for _, q := range s.Questions {
+			qk := checklistQuestionKey{checklist: cl.Label, section: s.Title, question: q.Question}
+			if _, ok := r.order[qk]; !ok {
+				r.order[qk] = len(r.order)
+			}
+
+			key := checklistReportKey{checklist: cl.Label, section: s.Title, question: q.Question, reviewer: reviewer}
+			qr, ok := r.questions[key]
+			if !ok {
+				qr = &ChecklistQuestionReport{
+					Checklist: cl.Label,
+					Section:   s.Title,
+					Question:  q.Question,
+					Reviewer:  reviewer,
+					order:     r.order[qk],
+				}
+				r.questions[key] = qr
+			}
+
+			switch q.State {
+			case Passed:
+				qr.Passed++
+			case Failed:
+				qr.Failed++
+				qr.FailedTickets = append(qr.FailedTickets, ticket)
+			case NotApplicable:
+				qr.NotApplicable++
+			default:
+				qr.TBD++
+				qr.TBDTickets = append(qr.TBDTickets, ticket)
+			}
+		}
+	}
+}
+
+// Questions returns the counts per question and reviewer, sorted by checklist
+// label, then in the order the questions appear in the checklists, then by
+// reviewer
+func (r *ChecklistReport) Questions() []*ChecklistQuestionReport {
+	result := make([]*ChecklistQuestionReport, 0, len(r.questions))
+	for _, qr := range r.questions {
+		result = append(result, qr)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Checklist != result[j].Checklist {
+			return result[i].Checklist < result[j].Checklist
+		}
+		if result[i].order != result[j].order {
+			return result[i].order < result[j].order
+		}
+		return result[i].Reviewer < result[j].Reviewer
+	})
+
+	for _, qr := range result {
+		sortIds(qr.FailedTickets)
+		sortIds(qr.TBDTickets)
+	}
+
+	return result
+}
+
+func sortIds(ids []entity.Id) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
@@ -0,0 +1,71 @@
+package bug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daedaleanai/git-ticket/entity"
+)
+
+func TestChecklistReport(t *testing.T) {
+	answered := func(states ...ChecklistState) ChecklistSnapshot {
+		cl := Checklist{Label: "checklist:code", Title: "Code",
+			Sections: []ChecklistSection{{Title: "Tests",
+				Questions: []ChecklistQuestion{{Question: "Tested?"}, {Question: "Reviewed?"}}}},
+		}
+		for i, s := range states {
+			cl.Sections[0].Questions[i].State = s
+		}
+		return ChecklistSnapshot{Checklist: cl}
+	}
+
+	configs := &ConfigCache{checklists: map[Label]Checklist{
+		"checklist:code": answered().Checklist,
+		"checklist:docs": {Label: "checklist:docs", Title: "Docs",
+			Sections: []ChecklistSection{{Title: "Manual", Questions: []ChecklistQuestion{{Question: "Updated?"}}}}},
+	}}
+
+	rene, isaac := entity.Id("aaaaaaaaaa"), entity.Id("bbbbbbbbbb")
+
+	snaps := []*Snapshot{
+		{id: "1111111111", Labels: []Label{"checklist:code", "checklist:docs"},
+			Checklists: map[Label]map[entity.Id]ChecklistSnapshot{
+				"checklist:code": {rene: answered(Passed, Failed), isaac: answered(Passed, Passed)},
+			}},
+		{id: "2222222222", Labels: []Label{"checklist:code"},
+			Checklists: map[Label]map[entity.Id]ChecklistSnapshot{
+				"checklist:code": {rene: answered(NotApplicable, TBD)},
+			}},
+		// Answers to a checklist the ticket doesn't have anymore aren't counted
+		{id: "3333333333", Labels: []Label{"bug"},
+			Checklists: map[Label]map[entity.Id]ChecklistSnapshot{
+				"checklist:code": {rene: answered(Failed, Failed)},
+			}},
+	}
+
+	report := NewChecklistReport()
+	for _, snap := range snaps {
+		report.Add(configs, snap)
+	}
+
+	questions := report.Questions()
+	require.Len(t, questions, 5)
+
+	assert.Equal(t, ChecklistQuestionReport{Checklist: "checklist:code", Section: "Tests", Question: "Tested?",
+		Reviewer: rene, Passed: 1, NotApplicable: 1}, *questions[0])
+	assert.Equal(t, isaac, questions[1].Reviewer)
+	assert.Equal(t, ChecklistQuestionReport{Checklist: "checklist:code", Section: "Tests", Question: "Reviewed?",
+		Reviewer: rene, Failed: 1, TBD: 1, order: 1,
+		FailedTickets: []entity.Id{"1111111111"}, TBDTickets: []entity.Id{"2222222222"}}, *questions[2])
+	assert.Equal(t, ChecklistQuestionReport{Checklist: "checklist:docs", Section: "Manual", Question: "Updated?",
+		TBD: 1, order: 2, TBDTickets: []entity.Id{"1111111111"}}, *questions[4])
+
+	// Only the given checklists
+	report = NewChecklistReport()
+	for _, snap := range snaps {
+		report.Add(configs, snap, "checklist:docs")
+	}
+	assert.Len(t, report.Questions(), 1)
+}
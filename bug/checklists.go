@@ -1,13 +1,10 @@
 package bug
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
-	"github.com/daedaleanai/git-ticket/config"
 	"github.com/daedaleanai/git-ticket/repository"
 	"github.com/daedaleanai/git-ticket/util/colors"
 )
@@ -25,6 +22,16 @@ type ChecklistQuestion struct {
 	Question string
 	Comment  string
 	State    ChecklistState
+	// Kind is the kind of answer expected in Value, constrained by Min, Max or
+	// Choices depending on the kind
+	Kind    QuestionKind `json:",omitempty"`
+	Min     *float64     `json:",omitempty"`
+	Max     *float64     `json:",omitempty"`
+	Choices []string     `json:",omitempty"`
+	Value   string       `json:",omitempty"`
+	// CommentRequired lists the states in which the question must have a
+	// comment, on top of the ones of the checklist
+	CommentRequired []string `json:",omitempty"`
 }
 type ChecklistSection struct {
 	Title     string
@@ -34,77 +41,18 @@ type Checklist struct {
 	Label    Label
 	Title    string
 	Sections []ChecklistSection
+	// CommentRequired lists the states in which all the questions must have a
+	// comment, e.g. ["FAILED", "NA"]
+	CommentRequired []string `json:",omitempty"`
+	// Version is the hash of the checklists config commit the template was
+	// read from, empty for checklists answered before templates were versioned
+	Version repository.Hash `json:",omitempty"`
 }
 type ChecklistSnapshot struct {
 	Checklist
 	LastEdit time.Time
 }
 
-var checklistStore map[Label]Checklist
-var repo repository.ClockedRepo
-
-// initChecklistStore attempts to read the checklists configuration out of the
-// current repository and use it to initialise the checklistStore
-func initChecklistStore() error {
-	cwd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("unable to get the current working directory: %q", err)
-	}
-
-	repo, err := repository.NewGitRepo(cwd, []repository.ClockLoader{ClockLoader})
-	if err == repository.ErrNotARepo {
-		return fmt.Errorf("must be run from within a git repo")
-	}
-
-	checklistData, err := config.GetConfig(repo, "checklists")
-	if err != nil {
-		return fmt.Errorf("unable to read checklists config: %q", err)
-	}
-
-	checklistStoreTemp := make(map[Label]Checklist)
-
-	err = json.Unmarshal(checklistData, &checklistStoreTemp)
-	if err != nil {
-		return fmt.Errorf("unable to load checklists: %q", err)
-	}
-
-	checklistStore = checklistStoreTemp
-
-	return nil
-}
-
-// GetChecklist returns a Checklist template out of the store
-func GetChecklist(label Label) (Checklist, error) {
-	if checklistStore == nil {
-		if err := initChecklistStore(); err != nil {
-			return Checklist{}, err
-		}
-	}
-
-	cl, present := checklistStore[label]
-
-	if !present {
-		return cl, fmt.Errorf("invalid checklist %s", label)
-	}
-
-	return cl, nil
-}
-
-// GetChecklistLabels returns a slice of all the available checklist labels
-func GetChecklistLabels() []Label {
-	if checklistStore == nil {
-		if err := initChecklistStore(); err != nil {
-			return nil
-		}
-	}
-
-	var labels []Label
-	for _, cl := range checklistStore {
-		labels = append(labels, cl.Label)
-	}
-	return labels
-}
-
 func (s ChecklistState) String() string {
 	switch s {
 	case TBD:
@@ -207,7 +155,11 @@ func (c Checklist) String() string {
 	for sn, s := range c.Sections {
 		result = result + fmt.Sprintf("#### %s ####\n", s.Title)
 		for qn, q := range s.Questions {
-			result = result + fmt.Sprintf("(%d.%d) %s [%s]\n", sn+1, qn+1, q.Question, q.State.ColorString())
+			result = result + fmt.Sprintf("(%d.%d) %s [%s]", sn+1, qn+1, q.Question, q.State.ColorString())
+			if q.Value != "" {
+				result = result + fmt.Sprintf(" %s", q.Value)
+			}
+			result = result + "\n"
 			if q.Comment != "" {
 				result = result + fmt.Sprintf("# %s\n", strings.Replace(q.Comment, "\n", "\n# ", -1))
 			}
@@ -215,3 +167,88 @@ func (c Checklist) String() string {
 	}
 	return result
 }
+
+// ChecklistUpgrade reports the changes made when upgrading a checklist to a
+// newer version of its template
+type ChecklistUpgrade struct {
+	From    repository.Hash
+	To      repository.Hash
+	Added   []string
+	Removed []string
+	// the questions whose kind of answer changed, they need a new answer
+	Changed []string
+}
+
+// IsNoop returns true if the upgrade doesn't change the checklist
+func (u ChecklistUpgrade) IsNoop() bool {
+	return u.From == u.To && len(u.Added) == 0 && len(u.Removed) == 0 && len(u.Changed) == 0
+}
+
+// UpgradeChecklist returns a copy of the template with the answers of the
+// checklist. Questions are matched on their text, answers to questions the
+// template doesn't have anymore are dropped and reported as removed. The
+// questions whose kind of answer changed are reset to TBD and reported as
+// changed, their answer doesn't fit anymore.
+func UpgradeChecklist(cl Checklist, template Checklist) (Checklist, ChecklistUpgrade) {
+	upgraded := template.clone()
+	upgrade := ChecklistUpgrade{From: cl.Version, To: template.Version}
+
+	// The answers not used yet, by question text, in order
+	answers := make(map[string][]*ChecklistQuestion)
+	for sn := range cl.Sections {
+		for qn := range cl.Sections[sn].Questions {
+			q := &cl.Sections[sn].Questions[qn]
+			answers[q.Question] = append(answers[q.Question], q)
+		}
+	}
+
+	for sn := range upgraded.Sections {
+		for qn := range upgraded.Sections[sn].Questions {
+			q := &upgraded.Sections[sn].Questions[qn]
+
+			matches := answers[q.Question]
+			if len(matches) == 0 {
+				upgrade.Added = append(upgrade.Added, q.Question)
+				continue
+			}
+
+			q.Comment = matches[0].Comment
+			if matches[0].Kind == q.Kind {
+				q.State = matches[0].State
+				q.Value = matches[0].Value
+			} else if matches[0].State != TBD {
+				upgrade.Changed = append(upgrade.Changed, q.Question)
+			}
+			answers[q.Question] = matches[1:]
+		}
+	}
+
+	for _, s := range cl.Sections {
+		for _, q := range s.Questions {
+			if len(answers[q.Question]) > 0 {
+				upgrade.Removed = append(upgrade.Removed, q.Question)
+				answers[q.Question] = answers[q.Question][1:]
+			}
+		}
+	}
+
+	return upgraded, upgrade
+}
+
+// clone returns a copy of the checklist that doesn't share its questions
+func (c Checklist) clone() Checklist {
+	sections := make([]ChecklistSection, len(c.Sections))
+	for i, s := range c.Sections {
+		sections[i] = ChecklistSection{
+			Title:     s.Title,
+			Questions: append([]ChecklistQuestion(nil), s.Questions...),
+		}
+		for j := range sections[i].Questions {
+			q := &sections[i].Questions[j]
+			q.Choices = append([]string(nil), q.Choices...)
+			q.CommentRequired = append([]string(nil), q.CommentRequired...)
+		}
+	}
+	c.Sections = sections
+	return c
+}
@@ -37,3 +37,95 @@ func TestChecklists_ChecklistCompoundState(t *testing.T) {
 	testChecklist.Sections[0].Questions[2].State = Failed
 	assert.Equal(t, testChecklist.CompoundState(), Failed)
 }
+
+func TestChecklists_UpgradeChecklist(t *testing.T) {
+	answered := Checklist{Label: "XYZ",
+		Title:   "XYZ Checklist",
+		Version: "1111111111111111111111111111111111111111",
+		Sections: []ChecklistSection{
+			ChecklistSection{Title: "ABC",
+				Questions: []ChecklistQuestion{
+					ChecklistQuestion{Question: "1?", Comment: "one", State: Passed},
+					ChecklistQuestion{Question: "2?", State: Failed},
+					ChecklistQuestion{Question: "3?", State: NotApplicable},
+				},
+			},
+		},
+	}
+	template := Checklist{Label: "XYZ",
+		Title:   "XYZ Checklist v2",
+		Version: "2222222222222222222222222222222222222222",
+		Sections: []ChecklistSection{
+			ChecklistSection{Title: "ABC",
+				Questions: []ChecklistQuestion{
+					ChecklistQuestion{Question: "3?"},
+					ChecklistQuestion{Question: "4?"},
+				},
+			},
+			ChecklistSection{Title: "DEF",
+				Questions: []ChecklistQuestion{
+					ChecklistQuestion{Question: "1?"},
+				},
+			},
+		},
+	}
+
+	upgraded, upgrade := UpgradeChecklist(answered, template)
+
+	assert.Equal(t, ChecklistUpgrade{
+		From:    answered.Version,
+		To:      template.Version,
+		Added:   []string{"4?"},
+		Removed: []string{"2?"},
+	}, upgrade)
+	assert.False(t, upgrade.IsNoop())
+
+	assert.Equal(t, "XYZ Checklist v2", upgraded.Title)
+	assert.Equal(t, template.Version, upgraded.Version)
+	assert.Equal(t, NotApplicable, upgraded.Sections[0].Questions[0].State)
+	assert.Equal(t, TBD, upgraded.Sections[0].Questions[1].State)
+	assert.Equal(t, ChecklistQuestion{Question: "1?", Comment: "one", State: Passed}, upgraded.Sections[1].Questions[0])
+
+	// The template isn't modified
+	assert.Equal(t, TBD, template.Sections[1].Questions[0].State)
+
+	_, upgrade = UpgradeChecklist(upgraded, template)
+	assert.True(t, upgrade.IsNoop())
+}
+
+func TestChecklists_UpgradeChecklistKindChange(t *testing.T) {
+	answered := Checklist{Label: "XYZ",
+		Version: "1111111111111111111111111111111111111111",
+		Sections: []ChecklistSection{
+			{Title: "ABC",
+				Questions: []ChecklistQuestion{
+					{Question: "Tested?", Kind: YesNoQuestion, Value: "yes", State: Passed},
+					{Question: "Coverage?", Kind: NumberQuestion, Value: "87", State: Passed},
+				},
+			},
+		},
+	}
+	template := Checklist{Label: "XYZ",
+		Version: "2222222222222222222222222222222222222222",
+		Sections: []ChecklistSection{
+			{Title: "ABC",
+				Questions: []ChecklistQuestion{
+					{Question: "Tested?", Kind: TextQuestion},
+					{Question: "Coverage?", Kind: NumberQuestion},
+				},
+			},
+		},
+	}
+
+	upgraded, upgrade := UpgradeChecklist(answered, template)
+
+	// The answer doesn't fit the new kind, the question is answered again
+	assert.Equal(t, []string{"Tested?"}, upgrade.Changed)
+	assert.Equal(t, TBD, upgraded.Sections[0].Questions[0].State)
+	assert.Empty(t, upgraded.Sections[0].Questions[0].Value)
+
+	assert.Equal(t, Passed, upgraded.Sections[0].Questions[1].State)
+	assert.Equal(t, "87", upgraded.Sections[0].Questions[1].Value)
+
+	assert.NoError(t, upgraded.Validate())
+}
@@ -0,0 +1,334 @@
+package bug
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/daedaleanai/git-ticket/config"
+	"github.com/daedaleanai/git-ticket/repository"
+)
+
+// ConfigCache holds the workflows, checklists and groups configured in a
+// repository. They are read from the repository the first time they are needed
+// and kept until Invalidate is called.
+//
+// A nil ConfigCache, or one without a repository, stands for a repository
+// without configuration: the default workflows and no checklists or groups.
+type ConfigCache struct {
+	repo repository.ClockedRepo
+
+	mu         sync.Mutex
+	workflows  []Workflow
+	checklists map[Label]Checklist
+	groups     Groups
+
+	// checklist templates of previous versions of the config, they never
+	// change so they are kept when the cache is invalidated
+	checklistVersions map[repository.Hash]map[Label]Checklist
+}
+
+// NewConfigCache returns a ConfigCache reading the configuration of the given repository
+func NewConfigCache(repo repository.ClockedRepo) *ConfigCache {
+	return &ConfigCache{repo: repo}
+}
+
+// Invalidate drops the configuration read so far, it is read again from the
+// repository the next time it's needed
+func (c *ConfigCache) Invalidate() {
+	if c == nil || c.repo == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.workflows = nil
+	c.checklists = nil
+	c.groups = nil
+}
+
+// readConfig returns the named configuration of the repository, or nil if it
+// doesn't exist
+func (c *ConfigCache) readConfig(name string) ([]byte, error) {
+	if c.repo == nil {
+		return nil, nil
+	}
+
+	data, err := config.GetConfig(c.repo, name)
+	if err == config.ErrConfigNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s config: %s", name, err)
+	}
+
+	return data, nil
+}
+
+// Workflows returns all the workflows, sorted by label. If the repository has
+// no workflows configuration the default workflows are returned.
+func (c *ConfigCache) Workflows() ([]Workflow, error) {
+	if c == nil {
+		return defaultWorkflows, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.workflows != nil {
+		return c.workflows, nil
+	}
+
+	data, err := c.readConfig("workflows")
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		c.workflows = defaultWorkflows
+		return c.workflows, nil
+	}
+
+	workflows, err := ParseWorkflows(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load workflows: %s", err)
+	}
+
+	c.workflows = workflows
+
+	return c.workflows, nil
+}
+
+// FindWorkflow returns the workflow with the given label, or nil if there isn't one
+func (c *ConfigCache) FindWorkflow(label Label) *Workflow {
+	workflows, err := c.Workflows()
+	if err != nil {
+		return nil
+	}
+
+	for i := range workflows {
+		if workflows[i].label == label {
+			return &workflows[i]
+		}
+	}
+	return nil
+}
+
+// WorkflowLabels returns the labels of all the workflows
+func (c *ConfigCache) WorkflowLabels() []Label {
+	workflows, err := c.Workflows()
+	if err != nil {
+		return nil
+	}
+
+	var labels []Label
+	for _, wf := range workflows {
+		labels = append(labels, wf.label)
+	}
+	return labels
+}
+
+// AllStatuses returns the built-in statuses followed by the statuses added by
+// the workflows
+func (c *ConfigCache) AllStatuses() []Status {
+	statuses := BuiltinStatuses()
+
+	workflows, err := c.Workflows()
+	if err != nil {
+		return statuses
+	}
+
+	for _, wf := range workflows {
+		for _, s := range wf.States() {
+			if !statusExist(statuses, s) {
+				statuses = append(statuses, s)
+			}
+		}
+	}
+
+	return statuses
+}
+
+// StatusFromString returns the status matching the given name, as long as it's
+// a built-in status or one defined by a workflow
+func (c *ConfigCache) StatusFromString(str string) (Status, error) {
+	cleaned := strings.ToLower(strings.TrimSpace(str))
+
+	for _, s := range c.AllStatuses() {
+		if string(s) == cleaned {
+			return s, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown status: %s", cleaned)
+}
+
+// Checklists returns all the checklist templates, mapped to their label. The
+// templates carry the version of the config they were read from.
+func (c *ConfigCache) Checklists() (map[Label]Checklist, error) {
+	if c == nil {
+		return make(map[Label]Checklist), nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.checklists != nil {
+		return c.checklists, nil
+	}
+
+	if c.repo == nil {
+		c.checklists = make(map[Label]Checklist)
+		return c.checklists, nil
+	}
+
+	data, version, err := config.GetConfigVersion(c.repo, "checklists")
+	if err == config.ErrConfigNotFound {
+		c.checklists = make(map[Label]Checklist)
+		return c.checklists, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read checklists config: %s", err)
+	}
+
+	checklists, err := parseChecklistsVersion(data, version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load checklists: %s", err)
+	}
+
+	c.checklists = checklists
+
+	return c.checklists, nil
+}
+
+// ChecklistAt returns the checklist template with the given label as it was in
+// the given version of the checklists config
+func (c *ConfigCache) ChecklistAt(label Label, version repository.Hash) (Checklist, error) {
+	if c == nil || c.repo == nil {
+		return Checklist{}, fmt.Errorf("checklist %s version %s not available", label, version)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	checklists, ok := c.checklistVersions[version]
+	if !ok {
+		data, err := config.GetConfigAt(c.repo, "checklists", version)
+		if err != nil {
+			return Checklist{}, fmt.Errorf("unable to read checklists config version %s: %s", version, err)
+		}
+
+		checklists, err = parseChecklistsVersion(data, version)
+		if err != nil {
+			return Checklist{}, fmt.Errorf("unable to load checklists version %s: %s", version, err)
+		}
+
+		if c.checklistVersions == nil {
+			c.checklistVersions = make(map[repository.Hash]map[Label]Checklist)
+		}
+		c.checklistVersions[version] = checklists
+	}
+
+	cl, present := checklists[label]
+	if !present {
+		return cl, fmt.Errorf("invalid checklist %s in version %s", label, version)
+	}
+
+	return cl.clone(), nil
+}
+
+// Checklist returns the checklist template with the given label
+func (c *ConfigCache) Checklist(label Label) (Checklist, error) {
+	checklists, err := c.Checklists()
+	if err != nil {
+		return Checklist{}, err
+	}
+
+	cl, present := checklists[label]
+	if !present {
+		return cl, fmt.Errorf("invalid checklist %s", label)
+	}
+
+	return cl.clone(), nil
+}
+
+// ChecklistLabels returns the labels of all the checklist templates, sorted
+func (c *ConfigCache) ChecklistLabels() []Label {
+	checklists, err := c.Checklists()
+	if err != nil {
+		return nil
+	}
+
+	var labels []Label
+	for _, cl := range checklists {
+		labels = append(labels, cl.Label)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i] < labels[j] })
+	return labels
+}
+
+// Groups returns the groups of identities. If they can't be read there are no
+// groups, so that restricted transitions are refused rather than allowed.
+func (c *ConfigCache) Groups() Groups {
+	if c == nil {
+		return make(Groups)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.groups != nil {
+		return c.groups
+	}
+
+	data, err := c.readConfig("groups")
+	if err != nil || data == nil {
+		return make(Groups)
+	}
+
+	groups, err := ParseGroups(data)
+	if err != nil {
+		return make(Groups)
+	}
+
+	c.groups = groups
+
+	return c.groups
+}
+
+// ParseChecklists decodes and validates a JSON checklists configuration, a map
+// of checklist label to checklist template
+func ParseChecklists(data []byte) (map[Label]Checklist, error) {
+	checklists := make(map[Label]Checklist)
+
+	if err := json.Unmarshal(data, &checklists); err != nil {
+		return nil, err
+	}
+
+	for label, cl := range checklists {
+		if err := cl.validateTemplate(); err != nil {
+			return nil, fmt.Errorf("%s: %s", label, err)
+		}
+	}
+
+	return checklists, nil
+}
+
+// parseChecklistsVersion decodes a JSON checklists configuration and sets the
+// given version on all the checklist templates
+func parseChecklistsVersion(data []byte, version repository.Hash) (map[Label]Checklist, error) {
+	checklists, err := ParseChecklists(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for label, cl := range checklists {
+		cl.Version = version
+		checklists[label] = cl
+	}
+
+	return checklists, nil
+}
@@ -0,0 +1,114 @@
+package bug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daedaleanai/git-ticket/config"
+	"github.com/daedaleanai/git-ticket/repository"
+)
+
+func TestConfigCache_Defaults(t *testing.T) {
+	for _, configs := range []*ConfigCache{nil, NewConfigCache(repository.NewMockRepoForTest())} {
+		workflows, err := configs.Workflows()
+		require.NoError(t, err)
+		assert.Equal(t, defaultWorkflows, workflows)
+		assert.NotNil(t, configs.FindWorkflow("workflow:eng"))
+
+		checklists, err := configs.Checklists()
+		require.NoError(t, err)
+		assert.Empty(t, checklists)
+		_, err = configs.Checklist("checklist:XYZ")
+		assert.Error(t, err)
+
+		assert.Empty(t, configs.Groups())
+
+		_, err = configs.StatusFromString("blocked")
+		assert.Error(t, err)
+	}
+}
+
+func TestConfigCache_Invalidate(t *testing.T) {
+	repo := repository.NewMockRepoForTest()
+	configs := NewConfigCache(repo)
+
+	require.NotNil(t, configs.FindWorkflow("workflow:eng"))
+
+	require.NoError(t, config.SetConfig(repo, "workflows", []byte(`{
+		"workflow:hw": {
+			"initialState": "proposed",
+			"transitions": [{"start": "proposed", "end": "blocked"}]
+		}
+	}`)))
+	require.NoError(t, config.SetConfig(repo, "checklists", []byte(`{
+		"checklist:hw": {"Label": "checklist:hw", "Title": "Hardware checklist"}
+	}`)))
+	require.NoError(t, config.SetConfig(repo, "groups", []byte(`{"hw-leads": ["a1b2c3d"]}`)))
+
+	// The configuration read before the change is kept until invalidated
+	assert.NotNil(t, configs.FindWorkflow("workflow:eng"))
+	assert.Nil(t, configs.FindWorkflow("workflow:hw"))
+
+	configs.Invalidate()
+
+	assert.Nil(t, configs.FindWorkflow("workflow:eng"))
+	assert.NotNil(t, configs.FindWorkflow("workflow:hw"))
+	assert.Equal(t, []Label{"workflow:hw"}, configs.WorkflowLabels())
+
+	status, err := configs.StatusFromString("Blocked")
+	assert.NoError(t, err)
+	assert.Equal(t, Status("blocked"), status)
+
+	cl, err := configs.Checklist("checklist:hw")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hardware checklist", cl.Title)
+	assert.Equal(t, []Label{"checklist:hw"}, configs.ChecklistLabels())
+
+	assert.Equal(t, Groups{"hw-leads": {"a1b2c3d"}}, configs.Groups())
+}
+
+func TestConfigCache_InvalidConfig(t *testing.T) {
+	repo := repository.NewMockRepoForTest()
+	require.NoError(t, config.SetConfig(repo, "workflows", []byte(`{"workflow:bad": {"initialState": "proposed", "transitions": [{"start": "proposed"}]}}`)))
+
+	configs := NewConfigCache(repo)
+
+	_, err := configs.Workflows()
+	assert.Error(t, err)
+	assert.Nil(t, configs.FindWorkflow("workflow:eng"))
+}
+
+func TestConfigCache_ChecklistVersions(t *testing.T) {
+	repo := repository.NewMockRepoForTest()
+	configs := NewConfigCache(repo)
+
+	require.NoError(t, config.SetConfig(repo, "checklists", []byte(`{
+		"checklist:hw": {"Label": "checklist:hw", "Title": "Hardware checklist"}
+	}`)))
+
+	v1, err := configs.Checklist("checklist:hw")
+	require.NoError(t, err)
+	require.True(t, v1.Version.IsValid())
+
+	require.NoError(t, config.SetConfig(repo, "checklists", []byte(`{
+		"checklist:hw": {"Label": "checklist:hw", "Title": "Hardware checklist v2"}
+	}`)))
+	configs.Invalidate()
+
+	v2, err := configs.Checklist("checklist:hw")
+	require.NoError(t, err)
+	assert.Equal(t, "Hardware checklist v2", v2.Title)
+	assert.NotEqual(t, v1.Version, v2.Version)
+
+	// Previous versions of the template stay available
+	pinned, err := configs.ChecklistAt("checklist:hw", v1.Version)
+	assert.NoError(t, err)
+	assert.Equal(t, v1, pinned)
+
+	_, err = configs.ChecklistAt("checklist:sw", v1.Version)
+	assert.Error(t, err)
+	_, err = configs.ChecklistAt("checklist:hw", "1234")
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package bug
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type GuardType string
+
+const (
+	// ChecklistsPassedGuard requires all the checklists of the ticket to be PASSED or NA
+	ChecklistsPassedGuard GuardType = "checklists-passed"
+	// ReviewAcceptedGuard requires at least one review of the ticket to be accepted
+	ReviewAcceptedGuard GuardType = "review-accepted"
+	// AssigneeSetGuard requires the ticket to have an assignee
+	AssigneeSetGuard GuardType = "assignee-set"
+	// LabelPresentGuard requires the ticket to have the guard label
+	LabelPresentGuard GuardType = "label-present"
+)
+
+// reviewAcceptedStatus is the overall Phabricator status of an accepted review
+const reviewAcceptedStatus = "accepted"
+
+// Guard is a condition a ticket must meet before a workflow transition is allowed
+type Guard struct {
+	Type  GuardType `json:"type"`
+	Label Label     `json:"label,omitempty"`
+}
+
+func (g Guard) String() string {
+	if g.Label != "" {
+		return fmt.Sprintf("%s(%s)", g.Type, g.Label)
+	}
+	return string(g.Type)
+}
+
+// Validate checks the guard is well formed
+func (g Guard) Validate() error {
+	switch g.Type {
+	case ChecklistsPassedGuard, ReviewAcceptedGuard, AssigneeSetGuard:
+		if g.Label != "" {
+			return fmt.Errorf("guard %s doesn't take a label", g.Type)
+		}
+	case LabelPresentGuard:
+		if err := g.Label.Validate(); err != nil {
+			return fmt.Errorf("guard %s label: %s", g.Type, err)
+		}
+	default:
+		return fmt.Errorf("unknown guard type %q", g.Type)
+	}
+
+	return nil
+}
+
+// Check returns an error explaining why the snapshot doesn't meet the guard
+// condition, or nil if it does
+func (g Guard) Check(snap *Snapshot) error {
+	switch g.Type {
+	case ChecklistsPassedGuard:
+		var notPassed []string
+		for label, state := range snap.GetChecklistCompoundStates() {
+			if state != Passed {
+				notPassed = append(notPassed, fmt.Sprintf("%s is %s", label, state))
+			}
+		}
+		if len(notPassed) > 0 {
+			sort.Strings(notPassed)
+			return fmt.Errorf("all checklists must be PASSED or NA: %s", strings.Join(notPassed, ", "))
+		}
+
+	case ReviewAcceptedGuard:
+		for _, r := range snap.Reviews {
+			if r.IsAccepted() {
+				return nil
+			}
+		}
+		return fmt.Errorf("at least one review must be accepted")
+
+	case AssigneeSetGuard:
+		if snap.Assignee == nil {
+			return fmt.Errorf("the ticket must be assigned")
+		}
+
+	case LabelPresentGuard:
+		if !labelExist(snap.Labels, g.Label) {
+			return fmt.Errorf("the ticket must have the label %s", g.Label)
+		}
+
+	default:
+		return fmt.Errorf("unknown guard type %q", g.Type)
+	}
+
+	return nil
+}
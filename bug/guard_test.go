@@ -0,0 +1,73 @@
+package bug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/identity"
+)
+
+func TestGuard_Validate(t *testing.T) {
+	assert.NoError(t, Guard{Type: ChecklistsPassedGuard}.Validate())
+	assert.NoError(t, Guard{Type: LabelPresentGuard, Label: "safety"}.Validate())
+
+	assert.Error(t, Guard{Type: "unknown"}.Validate())
+	assert.Error(t, Guard{Type: LabelPresentGuard}.Validate())
+	assert.Error(t, Guard{Type: AssigneeSetGuard, Label: "safety"}.Validate())
+}
+
+func TestGuard_Check(t *testing.T) {
+	snapshot := Snapshot{
+		Status:     ReviewedStatus,
+		Labels:     []Label{"workflow:test", "checklist:XYZ"},
+		Checklists: make(map[Label]map[entity.Id]ChecklistSnapshot),
+		Reviews:    make(map[string]ReviewInfo),
+	}
+
+	wf := Workflow{label: "workflow:test",
+		initialState: ProposedStatus,
+		transitions: []Transition{
+			Transition{start: ReviewedStatus, end: AcceptedStatus, guards: []Guard{
+				{Type: ChecklistsPassedGuard},
+				{Type: ReviewAcceptedGuard},
+				{Type: AssigneeSetGuard},
+				{Type: LabelPresentGuard, Label: "safety"},
+			}},
+		},
+	}
+
+	// Nothing is met, every guard is reported
+	err := wf.ValidateSnapshotTransition(&TransitionContext{Snapshot: &snapshot, To: AcceptedStatus})
+	assert.Error(t, err)
+	for _, g := range wf.transitions[0].guards {
+		assert.Contains(t, err.Error(), string(g.Type))
+	}
+
+	snapshot.Checklists["checklist:XYZ"] = map[entity.Id]ChecklistSnapshot{
+		"123": ChecklistSnapshot{Checklist: Checklist{Sections: []ChecklistSection{
+			{Questions: []ChecklistQuestion{{State: Passed}, {State: NotApplicable}}},
+		}}},
+	}
+	snapshot.Reviews["D1"] = ReviewInfo{RevisionId: "D1", Updates: []ReviewUpdate{
+		{PhabTransaction: PhabTransaction{Type: StatusTransaction, Status: "needs-review", Timestamp: 1}},
+		{PhabTransaction: PhabTransaction{Type: StatusTransaction, Status: "accepted", Timestamp: 2}},
+	}}
+	snapshot.Assignee = identity.NewBare("René Descartes", "rene@descartes.fr")
+
+	// Only the label is missing
+	err = wf.ValidateSnapshotTransition(&TransitionContext{Snapshot: &snapshot, To: AcceptedStatus})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), string(LabelPresentGuard))
+	assert.NotContains(t, err.Error(), string(ChecklistsPassedGuard))
+
+	snapshot.Labels = append(snapshot.Labels, "safety")
+	assert.NoError(t, wf.ValidateSnapshotTransition(&TransitionContext{Snapshot: &snapshot, To: AcceptedStatus}))
+
+	// An unaccepted review doesn't count
+	snapshot.Reviews["D1"] = ReviewInfo{RevisionId: "D1", Updates: []ReviewUpdate{
+		{PhabTransaction: PhabTransaction{Type: StatusTransaction, Status: "needs-revision", Timestamp: 3}},
+	}}
+	assert.Error(t, wf.ValidateSnapshotTransition(&TransitionContext{Snapshot: &snapshot, To: AcceptedStatus}))
+}
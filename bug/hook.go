@@ -0,0 +1,260 @@
+package bug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/daedaleanai/git-ticket/identity"
+)
+
+// defaultHookTimeout is how long a transition hook may run if the workflow
+// doesn't say otherwise
+const defaultHookTimeout = 30 * time.Second
+
+// TransitionContext describes the status change a transition is validated for
+type TransitionContext struct {
+	Snapshot *Snapshot
+	To       Status
+	Actor    identity.Interface
+	// Configs holds the workflows and groups of the repository
+	Configs *ConfigCache
+	// GitDir is the git directory of the repository holding the ticket
+	GitDir string
+	// Replay is set when an existing transition is replayed rather than a new
+	// one made, for example during a merge. Hooks are skipped in that case.
+	Replay bool
+}
+
+// hookIdentity is the JSON representation of an identity given to hooks
+type hookIdentity struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Login string `json:"login,omitempty"`
+}
+
+// hookTicket is the JSON representation of a ticket given to hooks
+type hookTicket struct {
+	Id         string            `json:"id"`
+	HumanId    string            `json:"human_id"`
+	Title      string            `json:"title"`
+	Status     Status            `json:"status"`
+	Labels     []Label           `json:"labels"`
+	Author     *hookIdentity     `json:"author,omitempty"`
+	Assignee   *hookIdentity     `json:"assignee,omitempty"`
+	CreateTime int64             `json:"create_time"`
+	EditTime   int64             `json:"edit_time"`
+	Checklists map[Label]string  `json:"checklists"`
+	Reviews    map[string]string `json:"reviews"`
+}
+
+// hookInput is the document written to the standard input of hooks
+type hookInput struct {
+	Ticket hookTicket    `json:"ticket"`
+	From   Status        `json:"from"`
+	To     Status        `json:"to"`
+	Actor  *hookIdentity `json:"actor,omitempty"`
+	GitDir string        `json:"git_dir"`
+}
+
+// hookResponse is the document a hook can write to its standard output to
+// explain why it vetoed the transition
+type hookResponse struct {
+	Reason string `json:"reason"`
+}
+
+func newHookIdentity(i identity.Interface) *hookIdentity {
+	if i == nil {
+		return nil
+	}
+	return &hookIdentity{
+		Id:    i.Id().String(),
+		Name:  i.Name(),
+		Email: i.Email(),
+		Login: i.Login(),
+	}
+}
+
+func newHookInput(ctx *TransitionContext) hookInput {
+	snap := ctx.Snapshot
+
+	ticket := hookTicket{
+		Id:         snap.Id().String(),
+		HumanId:    snap.Id().Human(),
+		Title:      snap.Title,
+		Status:     snap.Status,
+		Labels:     snap.Labels,
+		Author:     newHookIdentity(snap.Author),
+		Assignee:   newHookIdentity(snap.Assignee),
+		CreateTime: snap.CreateTime.Unix(),
+		EditTime:   snap.EditTime().Unix(),
+		Checklists: make(map[Label]string),
+		Reviews:    make(map[string]string),
+	}
+
+	for l, state := range snap.GetChecklistCompoundStates() {
+		ticket.Checklists[l] = state.String()
+	}
+
+	for id, r := range snap.Reviews {
+		ticket.Reviews[id] = r.LatestOverallStatus()
+	}
+
+	return hookInput{
+		Ticket: ticket,
+		From:   snap.Status,
+		To:     ctx.To,
+		Actor:  newHookIdentity(ctx.Actor),
+		GitDir: ctx.GitDir,
+	}
+}
+
+// environment returns the GIT_TICKET_* variables set for hooks
+func (ctx *TransitionContext) environment() []string {
+	env := []string{
+		"GIT_TICKET_ID=" + ctx.Snapshot.Id().String(),
+		"GIT_TICKET_HUMAN_ID=" + ctx.Snapshot.Id().Human(),
+		"GIT_TICKET_FROM=" + ctx.Snapshot.Status.String(),
+		"GIT_TICKET_TO=" + ctx.To.String(),
+		"GIT_TICKET_GIT_DIR=" + ctx.GitDir,
+	}
+
+	if ctx.Actor != nil {
+		env = append(env,
+			"GIT_TICKET_ACTOR_ID="+ctx.Actor.Id().String(),
+			"GIT_TICKET_ACTOR_NAME="+ctx.Actor.Name(),
+			"GIT_TICKET_ACTOR_EMAIL="+ctx.Actor.Email(),
+		)
+	}
+
+	return env
+}
+
+// runHook runs the transition hook, if any. The hook gets a JSON description
+// of the transition on its standard input and GIT_TICKET_* environment
+// variables. A non-zero exit status vetoes the transition, the hook can
+// explain why by writing {"reason": "..."} to its standard output.
+func (t *Transition) runHook(ctx *TransitionContext) error {
+	if t.hook == "" {
+		return nil
+	}
+
+	if ctx.Replay {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: hook of transition %s -> %s skipped while replaying ticket %s\n",
+			t.start, t.end, ctx.Snapshot.Id().Human())
+		return nil
+	}
+
+	hookArgs, err := splitHookCommand(t.hook)
+	if err != nil {
+		return fmt.Errorf("transition %s -> %s: invalid hook: %s", t.start, t.end, err)
+	}
+
+	input, err := json.Marshal(newHookInput(ctx))
+	if err != nil {
+		return err
+	}
+
+	timeout := t.HookTimeout()
+
+	runCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+
+	cmd := exec.CommandContext(runCtx, hookArgs[0], hookArgs[1:]...)
+	cmd.Env = append(os.Environ(), ctx.environment()...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+
+	if runCtx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("transition %s -> %s: hook timed out after %s", t.start, t.end, timeout)
+	}
+
+	// Anything other than a structured response is meant for the user
+	var response hookResponse
+	if jsonErr := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &response); jsonErr != nil {
+		_, _ = os.Stdout.Write(stdout.Bytes())
+	}
+
+	if err != nil {
+		if response.Reason != "" {
+			return fmt.Errorf("transition %s -> %s vetoed by hook: %s", t.start, t.end, response.Reason)
+		}
+		return fmt.Errorf("transition %s -> %s vetoed by hook: %s", t.start, t.end, err)
+	}
+
+	return nil
+}
+
+// splitHookCommand splits a hook command line into arguments the way a shell
+// would, honouring single quotes, double quotes and backslash escapes
+func splitHookCommand(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unmatched quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	return args, nil
+}
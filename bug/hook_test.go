@@ -0,0 +1,130 @@
+package bug
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/identity"
+)
+
+func TestHook_SplitCommand(t *testing.T) {
+	var commands = map[string][]string{
+		"true":                        {"true"},
+		"  echo   a  b ":              {"echo", "a", "b"},
+		`echo 'a b' "c d"`:            {"echo", "a b", "c d"},
+		`echo "say \"hi\"" 'it''s'`:   {"echo", `say "hi"`, "its"},
+		`echo a\ b ''`:                {"echo", "a b", ""},
+		`./scripts/check --msg="x y"`: {"./scripts/check", "--msg=x y"},
+	}
+
+	for command, expected := range commands {
+		args, err := splitHookCommand(command)
+		assert.NoError(t, err, command)
+		assert.Equal(t, expected, args, command)
+	}
+
+	for _, command := range []string{"", "   ", `echo 'a`, `echo "a`, `echo a\`} {
+		_, err := splitHookCommand(command)
+		assert.Error(t, err, command)
+	}
+}
+
+func TestHook_Context(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hook")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "out")
+
+	tr := Transition{start: ProposedStatus, end: VettedStatus,
+		hook: "sh -c 'cat > " + out + "; echo \"$GIT_TICKET_FROM $GIT_TICKET_TO $GIT_TICKET_ACTOR_EMAIL $GIT_TICKET_GIT_DIR\" >> " + out + "'"}
+
+	snapshot := Snapshot{
+		Status: ProposedStatus,
+		Title:  "the title",
+		Labels: []Label{"workflow:test", "checklist:code", "checklist:sw"},
+		Checklists: map[Label]map[entity.Id]ChecklistSnapshot{
+			"checklist:code": {
+				"reviewer": {Checklist: Checklist{Label: "checklist:code", Sections: []ChecklistSection{
+					{Questions: []ChecklistQuestion{{Question: "q", State: Passed}}},
+				}}},
+			},
+		},
+	}
+	actor := identity.NewBare("René Descartes", "rene@descartes.fr")
+
+	err = tr.runHook(&TransitionContext{Snapshot: &snapshot, To: VettedStatus, Actor: actor, GitDir: "/repo/.git"})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+
+	var input hookInput
+	reader := bytes.NewReader(data)
+	decoder := json.NewDecoder(reader)
+	require.NoError(t, decoder.Decode(&input))
+	assert.Equal(t, "the title", input.Ticket.Title)
+	assert.Equal(t, ProposedStatus, input.From)
+	assert.Equal(t, VettedStatus, input.To)
+	assert.Equal(t, "rene@descartes.fr", input.Actor.Email)
+	assert.Equal(t, "/repo/.git", input.GitDir)
+
+	// The hooks read the checklist states by name
+	var raw struct {
+		Ticket struct {
+			Checklists map[string]interface{} `json:"checklists"`
+		} `json:"ticket"`
+	}
+	require.NoError(t, json.NewDecoder(bytes.NewReader(data)).Decode(&raw))
+	assert.Equal(t, map[string]interface{}{"checklist:code": "PASSED", "checklist:sw": "TBD"}, raw.Ticket.Checklists)
+
+	rest, err := ioutil.ReadAll(io.MultiReader(decoder.Buffered(), reader))
+	require.NoError(t, err)
+	assert.Contains(t, string(rest), "proposed vetted rene@descartes.fr /repo/.git")
+}
+
+func TestHook_Veto(t *testing.T) {
+	ctx := &TransitionContext{Snapshot: &Snapshot{Status: ProposedStatus}, To: VettedStatus}
+
+	tr := Transition{start: ProposedStatus, end: VettedStatus, hook: "false"}
+	assert.Error(t, tr.runHook(ctx))
+
+	tr.hook = `sh -c 'echo "{\"reason\": \"the build is broken\"}"; exit 1'`
+	err := tr.runHook(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the build is broken")
+
+	tr.hook = "sleep 5"
+	tr.hookTimeout = 100 * time.Millisecond
+	err = tr.runHook(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestHook_Replay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hook")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ran := filepath.Join(dir, "ran")
+
+	// Replayed transitions neither run the hook nor fail because of it
+	tr := Transition{start: ProposedStatus, end: VettedStatus, hook: "sh -c 'touch " + ran + "; exit 1'"}
+	ctx := &TransitionContext{Snapshot: &Snapshot{Status: ProposedStatus}, To: VettedStatus, Replay: true}
+	assert.NoError(t, tr.runHook(ctx))
+	assert.NoFileExists(t, ran)
+
+	ctx.Replay = false
+	assert.Error(t, tr.runHook(ctx))
+	assert.FileExists(t, ran)
+}
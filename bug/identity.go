@@ -51,6 +51,10 @@ func (bug *Bug) EnsureIdentities(resolver identity.Resolver) error {
 		// and for review operations, each of the update authors
 		if setRev, ok := op.(*SetReviewOperation); ok {
 			for i, u := range setRev.Review.Updates {
+				// the users without identity are kept as is
+				if u.Author == nil {
+					continue
+				}
 				entity := u.Author.Id()
 
 				if _, ok := found[entity]; !ok {
@@ -181,7 +181,7 @@ func (l LabelChangeTimelineItem) String() string {
 func (l *LabelChangeTimelineItem) IsAuthored() {}
 
 // ChangeLabels is a convenience function to apply the operation
-func ChangeLabels(b Interface, author identity.Interface, unixTime int64, add, remove []string) ([]LabelChangeResult, *LabelChangeOperation, error) {
+func ChangeLabels(b Interface, author identity.Interface, unixTime int64, add, remove []string, configs *ConfigCache) ([]LabelChangeResult, *LabelChangeOperation, error) {
 	var added, removed []Label
 	var results []LabelChangeResult
 	var newWorkflow *Workflow
@@ -205,7 +205,7 @@ func ChangeLabels(b Interface, author identity.Interface, unixTime int64, add, r
 
 		// if it's a workflow, check it exists
 		if label.IsWorkflow() {
-			if newWorkflow = FindWorkflow(Label(str)); newWorkflow == nil {
+			if newWorkflow = configs.FindWorkflow(Label(str)); newWorkflow == nil {
 				results = append(results, LabelChangeResult{Label: label, Status: LabelChangeInvalidWorkflow})
 				continue
 			}
@@ -13,7 +13,8 @@ import (
 
 var _ Operation = &SetChecklistOperation{}
 
-// SetChecklistOperation will update the checklist associated with a ticket
+// SetChecklistOperation will update the checklist associated with a ticket. The
+// checklist records the version of the template it was answered under.
 type SetChecklistOperation struct {
 	OpBase
 	Checklist Checklist `json:"checklist"`
@@ -52,12 +53,12 @@ func (op *SetChecklistOperation) Validate() error {
 		return err
 	}
 
-	for _, s := range op.Checklist.Sections {
-		for _, q := range s.Questions {
-			if err := q.State.Validate(); err != nil {
-				return errors.Wrap(err, "state")
-			}
-		}
+	if op.Checklist.Version != "" && !op.Checklist.Version.IsValid() {
+		return fmt.Errorf("invalid template version %q", op.Checklist.Version)
+	}
+
+	if err := op.Checklist.Validate(); err != nil {
+		return errors.Wrap(err, "checklist")
 	}
 
 	return nil
@@ -48,3 +48,46 @@ func TestOpSetChecklist_SetChecklist(t *testing.T) {
 
 	assert.Equal(t, before, &after)
 }
+
+func TestOpSetChecklist_Version(t *testing.T) {
+	var rene = identity.NewBare("René Descarte", "rene@descartes.fr")
+	unix := time.Now().Unix()
+
+	op := NewSetChecklistOp(rene, unix, Checklist{Label: "123", Version: "1234"})
+	assert.Error(t, op.Validate())
+
+	op = NewSetChecklistOp(rene, unix, Checklist{Label: "123", Version: "0123456789abcdef0123456789abcdef01234567"})
+	assert.NoError(t, op.Validate())
+
+	data, err := json.Marshal(op)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Version":"0123456789abcdef0123456789abcdef01234567"`)
+
+	// Checklists answered before templates were versioned are stored as before
+	data, err = json.Marshal(NewSetChecklistOp(rene, unix, Checklist{Label: "123"}))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "Version")
+}
+
+func TestOpSetChecklist_CommentRequired(t *testing.T) {
+	var rene = identity.NewBare("René Descarte", "rene@descartes.fr")
+	unix := time.Now().Unix()
+
+	cl := Checklist{Label: "123",
+		CommentRequired: []string{"NA"},
+		Sections: []ChecklistSection{
+			ChecklistSection{Title: "Section 1",
+				Questions: []ChecklistQuestion{
+					ChecklistQuestion{Question: "1?", State: NotApplicable},
+				},
+			},
+		},
+	}
+
+	_, err := SetChecklist(NewBug(), rene, unix, cl)
+	assert.Error(t, err)
+
+	cl.Sections[0].Questions[0].Comment = "not relevant to this change"
+	_, err = SetChecklist(NewBug(), rene, unix, cl)
+	assert.NoError(t, err)
+}
@@ -46,10 +46,15 @@ func (op *SetReviewOperation) addToTimeline(snapshot *Snapshot) {
 			tl.Review.Updates = append(tl.Review.Updates, u)
 			timelineMap[u.Timestamp] = tl
 		} else {
-			// First one, create a new timeline item using the update author and timestamp
+			// First one, create a new timeline item using the update author and timestamp,
+			// or the author of the operation if the user has no identity
+			author := u.Author
+			if author == nil {
+				author = op.Author
+			}
 			item := &SetReviewTimelineItem{
 				id:       op.Id(),
-				Author:   u.Author,
+				Author:   author,
 				UnixTime: timestamp.Timestamp(u.Timestamp),
 				Review: ReviewInfo{
 					RevisionId: op.Review.RevisionId,
@@ -7,6 +7,7 @@ import (
 
 	"github.com/daedaleanai/git-ticket/identity"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var testUpdates = []ReviewUpdate{
@@ -128,3 +129,80 @@ func TestOpSetReview_Apply(t *testing.T) {
 
 	assert.NotContains(t, snapshot.Reviews, "D1234")
 }
+
+func TestReviewInfo_IsClosed(t *testing.T) {
+	review := ReviewInfo{RevisionId: "D1234", Updates: testUpdates}
+	assert.False(t, review.IsClosed())
+
+	for _, status := range []string{"published", "abandoned"} {
+		closed := review
+		closed.Updates = append(append([]ReviewUpdate{}, testUpdates...), ReviewUpdate{
+			PhabTransaction: PhabTransaction{
+				TransId:   "10020",
+				PhabUser:  "USERID1",
+				Timestamp: 20,
+				Type:      StatusTransaction,
+				Status:    status}})
+		assert.True(t, closed.IsClosed(), status)
+	}
+}
+
+func TestReviewInfo_Reviewers(t *testing.T) {
+	update := func(user string, typ TransactionType, status string) ReviewUpdate {
+		return ReviewUpdate{PhabTransaction: PhabTransaction{PhabUser: user, Type: typ, Status: status}}
+	}
+
+	review := ReviewInfo{RevisionId: "D1234", Updates: []ReviewUpdate{
+		update("author", UserStatusTransaction, "created"),
+		update("author", DiffTransaction, ""),
+		update("bob", UserStatusTransaction, "changes requested"),
+		update("bob", StatusTransaction, "needs-revision"),
+		update("carol", CommentTransaction, ""),
+		update("bob", UserStatusTransaction, "accepted"),
+	}}
+
+	var users []string
+	for _, u := range review.Reviewers() {
+		users = append(users, u.PhabUser)
+	}
+	assert.Equal(t, []string{"bob", "carol"}, users)
+	assert.Empty(t, ReviewInfo{}.Reviewers())
+}
+
+func TestReviewInfo_InlineThreads(t *testing.T) {
+	comment := func(id string, timestamp int64, diff int, path string, line int, done bool) ReviewUpdate {
+		return ReviewUpdate{PhabTransaction: PhabTransaction{
+			TransId:   id,
+			PhabUser:  "USERID1",
+			Timestamp: timestamp,
+			Type:      CommentTransaction,
+			Diff:      diff,
+			Path:      path,
+			Line:      line,
+			Done:      done,
+			Text:      "comment " + id}}
+	}
+
+	review := ReviewInfo{RevisionId: "D1234", Updates: []ReviewUpdate{
+		comment("1", 30, 2, "b.go", 4, true),
+		comment("2", 10, 2, "b.go", 4, false),
+		comment("3", 20, 1, "a.go", 7, false),
+		comment("4", 25, 2, "a.go", 3, false),
+		comment("5", 5, 0, "", 0, false),
+	}}
+
+	threads := review.InlineThreads()
+	require.Len(t, threads, 3)
+
+	assert.Equal(t, "a.go", threads[0].Path)
+	assert.Equal(t, 3, threads[0].Line)
+	assert.Equal(t, "a.go", threads[1].Path)
+	assert.Equal(t, 7, threads[1].Line)
+	assert.False(t, threads[1].Resolved())
+
+	assert.Equal(t, "b.go", threads[2].Path)
+	require.Len(t, threads[2].Comments, 2)
+	assert.Equal(t, "2", threads[2].Comments[0].TransId)
+	assert.Equal(t, "1", threads[2].Comments[1].TransId)
+	assert.True(t, threads[2].Resolved())
+}
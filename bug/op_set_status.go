@@ -139,15 +139,17 @@ func Close(b Interface, author identity.Interface, unixTime int64) (*SetStatusOp
 	return op, nil
 }
 
-// Convenience function to apply the operation
-func SetStatus(b Interface, author identity.Interface, unixTime int64, status Status) (*SetStatusOperation, error) {
+// Convenience function to apply the operation, gitDir is given to the
+// transition hook
+func SetStatus(b Interface, author identity.Interface, unixTime int64, status Status, configs *ConfigCache, gitDir string) (*SetStatusOperation, error) {
 	op := NewSetStatusOp(author, unixTime, status)
 	if err := op.Validate(); err != nil {
 		return nil, err
 	}
 
 	snap := b.Compile()
-	if err := snap.ValidateTransition(status); err != nil {
+	ctx := &TransitionContext{To: status, Actor: author, Configs: configs, GitDir: gitDir}
+	if err := snap.ValidateTransition(ctx); err != nil {
 		return nil, err
 	}
 	b.Append(op)
@@ -27,3 +27,20 @@ func TestSetStatusSerialize(t *testing.T) {
 
 	assert.Equal(t, before, &after)
 }
+
+func TestSetStatusLegacyDeserialize(t *testing.T) {
+	// Statuses used to be stored as the index of the built-in status
+	data := `{"type":4,"author":{"name":"René Descartes","email":"rene@descartes.fr"},"timestamp":1600000000,"status":3}`
+
+	var op SetStatusOperation
+	err := json.Unmarshal([]byte(data), &op)
+	assert.NoError(t, err)
+	assert.Equal(t, InProgressStatus, op.Status)
+	assert.NoError(t, op.Validate())
+
+	data = `{"type":4,"author":{"name":"René Descartes","email":"rene@descartes.fr"},"timestamp":1600000000,"status":42}`
+
+	err = json.Unmarshal([]byte(data), &op)
+	assert.NoError(t, err)
+	assert.Error(t, op.Validate())
+}
@@ -54,8 +54,9 @@ func TestValidate(t *testing.T) {
 		NewSetTitleOp(rene, unix, "title", "title2\u001b"),
 		NewAddCommentOp(rene, unix, "message\u001b", nil),
 		NewAddCommentOp(rene, unix, "message", []repository.Hash{repository.Hash("invalid")}),
-		NewSetStatusOp(rene, unix, 1000),
-		NewSetStatusOp(rene, unix, 0),
+		NewSetStatusOp(rene, unix, ""),
+		NewSetStatusOp(rene, unix, "multi\nline"),
+		NewSetStatusOp(rene, unix, "not a status"),
 		NewLabelChangeOperation(rene, unix, []Label{}, []Label{}),
 		NewLabelChangeOperation(rene, unix, []Label{"multi\nline"}, []Label{}),
 	}
@@ -0,0 +1,251 @@
+package bug
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/identity"
+)
+
+// minMemberIdLength is the shortest identity id prefix accepted as a group
+// member or allowed user, the length of a human id
+const minMemberIdLength = 7
+
+var groupNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// Groups maps a group name to the ids, or id prefixes, of its member identities
+type Groups map[string][]string
+
+// Permission restricts a transition to the members of some groups and to some
+// identities. An empty permission allows anyone.
+type Permission struct {
+	Groups []string `json:"groups,omitempty"`
+	Users  []string `json:"users,omitempty"`
+}
+
+// UnauthorizedTransition is a status change the workflow doesn't allow, either
+// not a transition of the workflow or made by an identity the transition is
+// restricted from
+type UnauthorizedTransition struct {
+	Op   *SetStatusOperation
+	From Status
+	Err  error
+}
+
+// ParseGroups decodes and validates a JSON groups configuration, a map of
+// group name to member identity ids
+func ParseGroups(data []byte) (Groups, error) {
+	groups := make(Groups)
+
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, err
+	}
+
+	for name, members := range groups {
+		if !groupNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid group name %q", name)
+		}
+		for _, m := range members {
+			if err := validateMemberId(m); err != nil {
+				return nil, fmt.Errorf("group %s: %s", name, err)
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+// IsMember returns true if the identity with the given id belongs to the group
+func (g Groups) IsMember(group string, id entity.Id) bool {
+	return matchMemberId(g[group], id)
+}
+
+// Validate checks the permission is well formed
+func (p *Permission) Validate() error {
+	for _, g := range p.Groups {
+		if !groupNameRegexp.MatchString(g) {
+			return fmt.Errorf("invalid group name %q", g)
+		}
+	}
+	for _, u := range p.Users {
+		if err := validateMemberId(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsEmpty returns true if the permission doesn't restrict anything
+func (p *Permission) IsEmpty() bool {
+	return p == nil || (len(p.Groups) == 0 && len(p.Users) == 0)
+}
+
+// Allows returns true if the identity is one of the users or belongs to one of
+// the groups of the permission
+func (p *Permission) Allows(actor identity.Interface, groups Groups) bool {
+	if p.IsEmpty() {
+		return true
+	}
+	if actor == nil {
+		return false
+	}
+
+	id := actor.Id()
+
+	if matchMemberId(p.Users, id) {
+		return true
+	}
+	for _, g := range p.Groups {
+		if groups.IsMember(g, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Permission) String() string {
+	var parts []string
+	for _, g := range p.Groups {
+		parts = append(parts, "group "+g)
+	}
+	for _, u := range p.Users {
+		parts = append(parts, "user "+entity.Id(u).Human())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Permission returns who is allowed to make the transition, nil if anyone is
+func (t *Transition) Permission() *Permission {
+	return t.allowed
+}
+
+// CheckPermission returns an error if the actor isn't allowed to make the transition
+func (t *Transition) CheckPermission(actor identity.Interface, groups Groups) error {
+	if t.allowed.Allows(actor, groups) {
+		return nil
+	}
+
+	if actor == nil {
+		return fmt.Errorf("transition %s -> %s is restricted to %s", t.start, t.end, t.allowed)
+	}
+
+	return fmt.Errorf("transition %s -> %s is restricted to %s, %s isn't allowed",
+		t.start, t.end, t.allowed, actor.DisplayName())
+}
+
+// CheckTransitionPermissions replays the operations of the snapshot and
+// returns the status changes the workflow of the ticket doesn't allow: the
+// ones which aren't transitions of the workflow and the ones made by
+// identities the transition is restricted from.
+//
+// Setting the initial state of a workflow right after assigning it, and the
+// status changes of a workflow migration, aren't transitions and are allowed.
+// The status of the tickets without workflow isn't restricted. The hooks of the
+// transitions aren't run again, they are skipped with a warning.
+func CheckTransitionPermissions(configs *ConfigCache, snap *Snapshot) []UnauthorizedTransition {
+	return checkTransitionPermissions(configs, snap, nil)
+}
+
+// CheckMergedTransitionPermissions checks the status changes the last merge
+// added to the bug as CheckTransitionPermissions does. The ones already in the
+// local history aren't reported again.
+func CheckMergedTransitionPermissions(configs *ConfigCache, b *Bug) []UnauthorizedTransition {
+	merged := make(map[entity.Id]bool)
+	for _, op := range b.MergedOperations() {
+		merged[op.Id()] = true
+	}
+
+	snap := b.Compile()
+	return checkTransitionPermissions(configs, &snap, merged)
+}
+
+// checkTransitionPermissions replays the operations of the snapshot and checks
+// its status changes, only the ones in the given set if it isn't nil
+func checkTransitionPermissions(configs *ConfigCache, snap *Snapshot, only map[entity.Id]bool) []UnauthorizedTransition {
+	var unauthorized []UnauthorizedTransition
+
+	replay := Snapshot{
+		id:         snap.id,
+		Status:     ProposedStatus,
+		Checklists: make(map[Label]map[entity.Id]ChecklistSnapshot),
+		Reviews:    make(map[string]ReviewInfo),
+	}
+
+	var previous Operation
+	for _, op := range snap.Operations {
+		if statusOp, ok := op.(*SetStatusOperation); ok && (only == nil || only[op.Id()]) {
+			if w, err := replay.Workflow(configs); err == nil {
+				if err := checkStatusChange(w, configs, &replay, statusOp, previous); err != nil {
+					unauthorized = append(unauthorized, UnauthorizedTransition{
+						Op:   statusOp,
+						From: replay.Status,
+						Err:  err,
+					})
+				}
+			}
+		}
+
+		op.Apply(&replay)
+		replay.Operations = append(replay.Operations, op)
+		previous = op
+	}
+
+	return unauthorized
+}
+
+// checkStatusChange returns an error if the workflow doesn't allow the status
+// change from the status of the replayed snapshot, previous being the operation
+// before it. The hook of the transition is skipped with a warning.
+func checkStatusChange(w *Workflow, configs *ConfigCache, replay *Snapshot, op *SetStatusOperation, previous Operation) error {
+	if t := w.findTransition(replay.Status, op.Status); t != nil {
+		if err := t.CheckPermission(op.Author, configs.Groups()); err != nil {
+			return err
+		}
+		return t.runHook(&TransitionContext{
+			Snapshot: replay,
+			To:       op.Status,
+			Actor:    op.Author,
+			Configs:  configs,
+			Replay:   true,
+		})
+	}
+
+	if _, ok := op.GetMetadata(MigrationMetadataKey); ok {
+		return nil
+	}
+
+	if labelOp, ok := previous.(*LabelChangeOperation); ok && op.Status == w.initialState {
+		for _, l := range labelOp.Added {
+			if l == w.label {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("invalid transition %s -> %s", replay.Status, op.Status)
+}
+
+func validateMemberId(id string) error {
+	if len(id) < minMemberIdLength {
+		return fmt.Errorf("identity id %q is too short", id)
+	}
+	for _, r := range id {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return fmt.Errorf("invalid identity id %q", id)
+		}
+	}
+	return nil
+}
+
+func matchMemberId(members []string, id entity.Id) bool {
+	for _, m := range members {
+		if id.HasPrefix(m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package bug
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/identity"
+	"github.com/daedaleanai/git-ticket/repository"
+)
+
+func TestPermission_ParseGroups(t *testing.T) {
+	groups, err := ParseGroups([]byte(`{"qa-leads": ["a1b2c3d", "e4f5a6b7c8"]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, Groups{"qa-leads": {"a1b2c3d", "e4f5a6b7c8"}}, groups)
+
+	var invalidConfigs = []string{
+		`[]`,
+		`{"QA leads": ["a1b2c3d"]}`,
+		`{"qa-leads": ["a1b2"]}`,
+		`{"qa-leads": ["not an id"]}`,
+	}
+
+	for _, c := range invalidConfigs {
+		_, err := ParseGroups([]byte(c))
+		assert.Error(t, err, c)
+	}
+}
+
+func TestPermission_Allows(t *testing.T) {
+	rene := identity.NewBare("René Descartes", "rene@descartes.fr")
+	isaac := identity.NewBare("Isaac Newton", "isaac@newton.uk")
+
+	groups := Groups{"qa-leads": {rene.Id().Human()}}
+
+	var anyone *Permission
+	assert.True(t, anyone.Allows(isaac, groups))
+	assert.True(t, (&Permission{}).Allows(nil, groups))
+
+	qaLeads := &Permission{Groups: []string{"qa-leads"}}
+	assert.True(t, qaLeads.Allows(rene, groups))
+	assert.False(t, qaLeads.Allows(isaac, groups))
+	assert.False(t, qaLeads.Allows(nil, groups))
+
+	isaacOnly := &Permission{Users: []string{isaac.Id().String()}}
+	assert.True(t, isaacOnly.Allows(isaac, groups))
+	assert.False(t, isaacOnly.Allows(rene, groups))
+
+	assert.Error(t, (&Permission{Groups: []string{"QA"}}).Validate())
+	assert.Error(t, (&Permission{Users: []string{"abc"}}).Validate())
+}
+
+func TestPermission_CheckTransitionPermissions(t *testing.T) {
+	rene := identity.NewBare("René Descartes", "rene@descartes.fr")
+	isaac := identity.NewBare("Isaac Newton", "isaac@newton.uk")
+	unix := time.Now().Unix()
+
+	configs := &ConfigCache{workflows: []Workflow{{label: "workflow:test",
+		initialState: ProposedStatus,
+		transitions: []Transition{
+			{start: ProposedStatus, end: InProgressStatus},
+			{start: InProgressStatus, end: AcceptedStatus, allowed: &Permission{Groups: []string{"qa-leads"}}},
+		},
+	}},
+		groups: Groups{"qa-leads": {rene.Id().String()}},
+	}
+
+	b := NewBug()
+	b.Append(NewCreateOp(isaac, unix, "title", "message", nil))
+	b.Append(NewLabelChangeOperation(isaac, unix, []Label{"workflow:test"}, nil))
+
+	// Not a qa lead
+	_, err := SetStatus(b, isaac, unix, InProgressStatus, configs, "")
+	require.NoError(t, err)
+	_, err = SetStatus(b, isaac, unix, AcceptedStatus, configs, "")
+	assert.Error(t, err)
+	_, err = SetStatus(b, rene, unix, AcceptedStatus, configs, "")
+	require.NoError(t, err)
+
+	snap := b.Compile()
+	assert.Empty(t, CheckTransitionPermissions(configs, &snap))
+
+	// A status change made without the checks, as if pulled from a remote
+	b.Append(NewSetStatusOp(isaac, unix, InProgressStatus))
+	b.Append(NewSetStatusOp(isaac, unix, AcceptedStatus))
+
+	snap = b.Compile()
+	unauthorized := CheckTransitionPermissions(configs, &snap)
+	require.Len(t, unauthorized, 2)
+
+	// accepted -> inprogress isn't a transition of the workflow
+	assert.Equal(t, AcceptedStatus, unauthorized[0].From)
+	assert.Equal(t, InProgressStatus, unauthorized[0].Op.Status)
+	assert.Contains(t, unauthorized[0].Err.Error(), "invalid transition")
+
+	assert.Equal(t, InProgressStatus, unauthorized[1].From)
+	assert.Equal(t, AcceptedStatus, unauthorized[1].Op.Status)
+	assert.Equal(t, isaac, unauthorized[1].Op.Author)
+}
+
+func TestPermission_CheckTransitionPermissionsNoTransition(t *testing.T) {
+	rene := identity.NewBare("René Descartes", "rene@descartes.fr")
+	unix := time.Now().Unix()
+
+	test := Workflow{label: "workflow:test",
+		initialState: ProposedStatus,
+		transitions: []Transition{
+			{start: ProposedStatus, end: InProgressStatus},
+		},
+	}
+	other := Workflow{label: "workflow:other",
+		initialState: VettedStatus,
+		transitions: []Transition{
+			{start: VettedStatus, end: DoneStatus},
+		},
+	}
+	configs := &ConfigCache{workflows: []Workflow{test, other}}
+
+	b := NewBug()
+	b.Append(NewCreateOp(rene, unix, "title", "message", nil))
+
+	// Setting the initial state of the workflow assigned
+	_, _, err := ChangeLabels(b, rene, unix, []string{"workflow:other"}, nil, configs)
+	require.NoError(t, err)
+	_, err = SetStatus(b, rene, unix, DoneStatus, configs, "")
+	require.NoError(t, err)
+
+	// Moving the ticket to another workflow
+	_, _, err = MigrateWorkflow(b, rene, unix, &test, map[Status]Status{DoneStatus: InProgressStatus})
+	require.NoError(t, err)
+
+	snap := b.Compile()
+	assert.Empty(t, CheckTransitionPermissions(configs, &snap))
+
+	// A status change pulled from a remote jumping straight to a state
+	b.Append(NewSetStatusOp(rene, unix, ProposedStatus))
+
+	snap = b.Compile()
+	unauthorized := CheckTransitionPermissions(configs, &snap)
+	require.Len(t, unauthorized, 1)
+	assert.Equal(t, InProgressStatus, unauthorized[0].From)
+	assert.Equal(t, ProposedStatus, unauthorized[0].Op.Status)
+}
+
+func TestPermission_CheckTransitionPermissionsHook(t *testing.T) {
+	rene := identity.NewBare("René Descartes", "rene@descartes.fr")
+	unix := time.Now().Unix()
+
+	configs := &ConfigCache{workflows: []Workflow{{label: "workflow:test",
+		initialState: ProposedStatus,
+		transitions: []Transition{
+			{start: ProposedStatus, end: InProgressStatus, hook: "false"},
+		},
+	}}}
+
+	b := NewBug()
+	b.Append(NewCreateOp(rene, unix, "title", "message", nil))
+	b.Append(NewLabelChangeOperation(rene, unix, []Label{"workflow:test"}, nil))
+
+	// The hook would veto the transition, but it isn't run when replaying it
+	b.Append(NewSetStatusOp(rene, unix, InProgressStatus))
+
+	snap := b.Compile()
+	assert.Empty(t, CheckTransitionPermissions(configs, &snap))
+}
+
+func TestPermission_CheckMergedTransitionPermissions(t *testing.T) {
+	repoA, repoB, remote := repository.SetupReposAndRemote()
+	defer repository.CleanupTestRepos(repoA, repoB, remote)
+
+	repository.SetupSigningKey(t, repoA, "a@e.org")
+	repository.SetupSigningKey(t, repoB, "a@e.org")
+
+	rene := identity.NewIdentity("René Descartes", "rene@descartes.fr")
+	require.NoError(t, rene.Commit(repoA))
+	_, err := identity.Push(repoA, "origin")
+	require.NoError(t, err)
+	require.NoError(t, identity.Pull(repoB, "origin"))
+
+	configs := &ConfigCache{workflows: []Workflow{{label: "workflow:test",
+		initialState: ProposedStatus,
+		transitions: []Transition{
+			{start: ProposedStatus, end: InProgressStatus},
+		},
+	}}}
+	unix := time.Now().Unix()
+
+	// pull merges the remote bugs and returns the status changes it reports
+	pull := func() []UnauthorizedTransition {
+		_, err := Fetch(repoB, "origin")
+		require.NoError(t, err)
+
+		var unauthorized []UnauthorizedTransition
+		for result := range MergeAll(repoB, "origin") {
+			require.NoError(t, result.Err)
+			if result.Status == entity.MergeStatusNew || result.Status == entity.MergeStatusUpdated {
+				unauthorized = append(unauthorized, CheckMergedTransitionPermissions(configs, result.Entity.(*Bug))...)
+			}
+		}
+		return unauthorized
+	}
+
+	b, _, err := Create(rene, unix, "title", "message")
+	require.NoError(t, err)
+	b.Append(NewLabelChangeOperation(rene, unix, []Label{"workflow:test"}, nil))
+	b.Append(NewSetStatusOp(rene, unix, DoneStatus))
+	require.NoError(t, b.Commit(repoA))
+	_, err = Push(repoA, "origin")
+	require.NoError(t, err)
+
+	unauthorized := pull()
+	require.Len(t, unauthorized, 1)
+	assert.Equal(t, DoneStatus, unauthorized[0].Op.Status)
+
+	// The status change already merged isn't reported again
+	_, err = AddComment(b, rene, unix, "another comment")
+	require.NoError(t, err)
+	require.NoError(t, b.Commit(repoA))
+	_, err = Push(repoA, "origin")
+	require.NoError(t, err)
+
+	assert.Empty(t, pull())
+
+	// Only the new one is
+	b.Append(NewSetStatusOp(rene, unix, ProposedStatus))
+	require.NoError(t, b.Commit(repoA))
+	_, err = Push(repoA, "origin")
+	require.NoError(t, err)
+
+	unauthorized = pull()
+	require.Len(t, unauthorized, 1)
+	assert.Equal(t, ProposedStatus, unauthorized[0].Op.Status)
+}
@@ -0,0 +1,60 @@
+package bug
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/daedaleanai/git-ticket/repository"
+)
+
+// reviewTicketTrailerConfigKey is the git config key of the trailer referencing
+// tickets in the summary of the reviews, "Ticket" if unset
+const reviewTicketTrailerConfigKey = "daedalean.review-ticket-trailer"
+
+const defaultReviewTicketTrailer = "Ticket"
+
+// ticketPrefixRegex matches the words which could be the prefix of a ticket id,
+// at least as long as its human form
+var ticketPrefixRegex = regexp.MustCompile(`\b[0-9a-fA-F]{7,64}\b`)
+
+// ReviewTicketTrailer returns the trailer referencing tickets in the summary of
+// the reviews, e.g. "Ticket" for "Ticket: abc1234"
+func ReviewTicketTrailer(repo repository.RepoConfig) string {
+	if trailer := readReviewConfig(repo, reviewTicketTrailerConfigKey); trailer != "" {
+		return trailer
+	}
+	return defaultReviewTicketTrailer
+}
+
+// ReviewTicketRefs returns the ticket id prefixes referenced by the given texts
+// of a review. The explicit ones are given in trailer lines, the mentioned ones
+// are the other words looking like a ticket id.
+func ReviewTicketRefs(trailer string, texts ...string) (explicit []string, mentioned []string) {
+	trailerRegex := regexp.MustCompile(`(?im)^\s*` + regexp.QuoteMeta(trailer) + `\s*:(.*)$`)
+
+	seen := make(map[string]bool)
+	add := func(refs []string, ref string) []string {
+		ref = strings.ToLower(ref)
+		if seen[ref] {
+			return refs
+		}
+		seen[ref] = true
+		return append(refs, ref)
+	}
+
+	for _, text := range texts {
+		for _, line := range trailerRegex.FindAllStringSubmatch(text, -1) {
+			for _, ref := range ticketPrefixRegex.FindAllString(line[1], -1) {
+				explicit = add(explicit, ref)
+			}
+		}
+	}
+
+	for _, text := range texts {
+		for _, ref := range ticketPrefixRegex.FindAllString(text, -1) {
+			mentioned = add(mentioned, ref)
+		}
+	}
+
+	return explicit, mentioned
+}
@@ -0,0 +1,20 @@
+package bug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewTicketRefs(t *testing.T) {
+	explicit, mentioned := ReviewTicketRefs("Ticket",
+		"[c0ffee1] Add the frobnicator",
+		"Frobnicates the widgets, see deadbeef12 and 12345.\n\nTicket: 1234ABC, 7654321\nticket:  1234abc\nTickets: aaaaaaa")
+
+	assert.Equal(t, []string{"1234abc", "7654321"}, explicit)
+	assert.Equal(t, []string{"c0ffee1", "deadbeef12", "aaaaaaa"}, mentioned)
+
+	explicit, mentioned = ReviewTicketRefs("Fixes", "Fixes: abcdef0")
+	assert.Equal(t, []string{"abcdef0"}, explicit)
+	assert.Empty(t, mentioned)
+}
@@ -0,0 +1,369 @@
+package bug
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// git config keys of the Gerrit server and of the credentials to query it with
+const (
+	gerritUrlConfigKey   = "daedalean.gerrit-url"
+	gerritUserConfigKey  = "daedalean.gerrit-user"
+	gerritTokenConfigKey = "daedalean.gerrit-token"
+)
+
+// gerritTimeLayout is the format of the timestamps returned by Gerrit, in UTC
+const gerritTimeLayout = "2006-01-02 15:04:05.000000000"
+
+// gerritJsonPrefix prefixes every JSON response of Gerrit
+var gerritJsonPrefix = []byte(")]}'")
+
+// gerritVoteRegex matches the Code-Review votes in the first line of a message,
+// e.g. "Patch Set 2: Code-Review+2"
+var gerritVoteRegex = regexp.MustCompile(`Code-Review([+-]\d)`)
+
+// gerritVoteStatuses maps the Code-Review votes approving or blocking a change
+// on to user statuses, the advisory -1 and +1 votes aren't statuses
+var gerritVoteStatuses = map[string]string{
+	"+2": "accepted",
+	"-2": "changes requested",
+}
+
+// GerritProvider fetches changes from Gerrit through its REST API. Changes are
+// identified by their number prefixed by G, e.g. G1234.
+type GerritProvider struct {
+	url    string
+	user   string
+	token  string
+	client *http.Client
+}
+
+var _ ReviewProvider = &GerritProvider{}
+
+// NewGerritProvider returns a provider querying the Gerrit server at the given
+// url. If a user is given the requests are authenticated with their HTTP
+// password token. A nil client uses the default HTTP client.
+func NewGerritProvider(url, user, token string, client *http.Client) *GerritProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &GerritProvider{
+		url:    strings.TrimSuffix(url, "/"),
+		user:   user,
+		token:  token,
+		client: client,
+	}
+}
+
+func (p *GerritProvider) Name() string {
+	return "gerrit"
+}
+
+func (p *GerritProvider) IdPrefix() string {
+	return "G"
+}
+
+// RevisionRef returns the ref Gerrit stores the patch set of the change at,
+// e.g. refs/changes/34/1234/2
+func (p *GerritProvider) RevisionRef(id string, diff int) string {
+	number := strings.TrimPrefix(id, p.IdPrefix())
+	shard := number
+	if len(shard) > 2 {
+		shard = shard[len(shard)-2:]
+	} else if len(shard) < 2 {
+		shard = "0" + shard
+	}
+	return fmt.Sprintf("refs/changes/%s/%s/%d", shard, number, diff)
+}
+
+type gerritAccount struct {
+	AccountId int    `json:"_account_id"`
+	Email     string `json:"email"`
+}
+
+// user returns the identifier of the account stored in the transactions
+func (a gerritAccount) user() string {
+	if a.Email != "" {
+		return a.Email
+	}
+	return fmt.Sprintf("gerrit:%d", a.AccountId)
+}
+
+type gerritChange struct {
+	Number  int           `json:"_number"`
+	Subject string        `json:"subject"`
+	Status  string        `json:"status"`
+	Updated string        `json:"updated"`
+	Owner   gerritAccount `json:"owner"`
+	Labels  map[string]struct {
+		Approved *gerritAccount `json:"approved"`
+		Rejected *gerritAccount `json:"rejected"`
+	} `json:"labels"`
+	Messages  []gerritMessage           `json:"messages"`
+	Revisions map[string]gerritRevision `json:"revisions"`
+}
+
+type gerritMessage struct {
+	Id             string        `json:"id"`
+	Author         gerritAccount `json:"author"`
+	Date           string        `json:"date"`
+	Text           string        `json:"message"`
+	Tag            string        `json:"tag"`
+	RevisionNumber int           `json:"_revision_number"`
+}
+
+type gerritRevision struct {
+	Number   int           `json:"_number"`
+	Created  string        `json:"created"`
+	Uploader gerritAccount `json:"uploader"`
+}
+
+type gerritComment struct {
+	Id         string        `json:"id"`
+	PatchSet   int           `json:"patch_set"`
+	Line       int           `json:"line"`
+	Text       string        `json:"message"`
+	Updated    string        `json:"updated"`
+	Author     gerritAccount `json:"author"`
+	Unresolved *bool         `json:"unresolved"`
+}
+
+// FetchReviewInfo maps the patch sets, Code-Review votes, messages and inline
+// comments of a Gerrit change on to review updates. The last transaction is the
+// time of the last update of the change, if a since transaction is given only
+// the updates made at or after it are returned. Gerrit times are only kept to
+// the second, so the updates made at the since time are returned again and
+// must be dropped with ReviewInfo.RemoveKnownUpdates.
+func (p *GerritProvider) FetchReviewInfo(id string, since string) (*ReviewInfo, error) {
+	if p.url == "" {
+		return nil, fmt.Errorf("no Gerrit server configured, set it with git config %s", gerritUrlConfigKey)
+	}
+
+	number := strings.TrimPrefix(id, p.IdPrefix())
+	if !reviewNumberRegex.MatchString(number) {
+		return nil, fmt.Errorf("gerrit change id '%s' unexpected format (Gnnn)", id)
+	}
+
+	var sinceTime int64
+	if since != "" {
+		var err error
+		sinceTime, err = strconv.ParseInt(since, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid last transaction '%s' for %s", since, id)
+		}
+	}
+
+	var change gerritChange
+	err := p.get("/changes/"+number+"?o=MESSAGES&o=ALL_REVISIONS&o=DETAILED_LABELS&o=DETAILED_ACCOUNTS", &change)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments map[string][]gerritComment
+	if err := p.get("/changes/"+number+"/comments", &comments); err != nil {
+		return nil, err
+	}
+
+	result := ReviewInfo{RevisionId: id, Title: change.Subject}
+
+	updated, err := gerritTime(change.Updated)
+	if err != nil {
+		return nil, err
+	}
+	result.LastTransaction = strconv.FormatInt(updated, 10)
+
+	var updates []ReviewUpdate
+	add := func(u PhabTransaction) {
+		if u.Timestamp >= sinceTime {
+			updates = append(updates, ReviewUpdate{PhabTransaction: u})
+		}
+	}
+
+	for _, r := range change.Revisions {
+		created, err := gerritTime(r.Created)
+		if err != nil {
+			return nil, err
+		}
+		add(PhabTransaction{
+			TransId:   "ps" + strconv.Itoa(r.Number),
+			PhabUser:  r.Uploader.user(),
+			Timestamp: created,
+			Type:      DiffTransaction,
+			DiffId:    r.Number,
+		})
+	}
+
+	for _, m := range change.Messages {
+		// Uploads, merges and the like are already covered by the patch sets and
+		// the status of the change
+		if strings.HasPrefix(m.Tag, "autogenerated:") {
+			continue
+		}
+
+		date, err := gerritTime(m.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		firstLine, text := m.Text, ""
+		if i := strings.Index(m.Text, "\n"); i >= 0 {
+			firstLine, text = m.Text[:i], strings.TrimSpace(m.Text[i+1:])
+		}
+
+		// Only the votes approving or blocking the change are statuses
+		if vote := gerritVoteRegex.FindStringSubmatch(firstLine); vote != nil && gerritVoteStatuses[vote[1]] != "" {
+			status := gerritVoteStatuses[vote[1]]
+			add(PhabTransaction{
+				TransId:   fmt.Sprintf("%s/ps%d", m.Id, m.RevisionNumber),
+				PhabUser:  m.Author.user(),
+				Timestamp: date,
+				Type:      UserStatusTransaction,
+				Status:    status,
+			})
+		}
+
+		if text != "" {
+			add(PhabTransaction{
+				TransId:   m.Id,
+				PhabUser:  m.Author.user(),
+				Timestamp: date,
+				Type:      CommentTransaction,
+				Text:      text,
+			})
+		}
+	}
+
+	for path, fileComments := range comments {
+		for _, c := range fileComments {
+			date, err := gerritTime(c.Updated)
+			if err != nil {
+				return nil, err
+			}
+			add(PhabTransaction{
+				TransId:   c.Id,
+				PhabUser:  c.Author.user(),
+				Timestamp: date,
+				Type:      CommentTransaction,
+				Diff:      c.PatchSet,
+				Path:      path,
+				Line:      c.Line,
+				Done:      c.Unresolved != nil && !*c.Unresolved,
+				Text:      c.Text,
+			})
+		}
+	}
+
+	add(PhabTransaction{
+		TransId:   change.statusId(),
+		PhabUser:  change.lastActor(),
+		Timestamp: updated,
+		Type:      StatusTransaction,
+		Status:    change.status(),
+	})
+
+	sort.Slice(updates, func(i, j int) bool {
+		if updates[i].Timestamp != updates[j].Timestamp {
+			return updates[i].Timestamp < updates[j].Timestamp
+		}
+		if updates[i].Type != updates[j].Type {
+			return updates[i].Type < updates[j].Type
+		}
+		return updates[i].TransId < updates[j].TransId
+	})
+	result.Updates = updates
+
+	return &result, nil
+}
+
+// status maps the state of the change on to the overall status of a
+// Phabricator revision
+func (c gerritChange) status() string {
+	switch c.Status {
+	case "MERGED":
+		return "published"
+	case "ABANDONED":
+		return "abandoned"
+	}
+
+	if c.Labels["Code-Review"].Rejected != nil {
+		return "needs-revision"
+	}
+	if c.Labels["Code-Review"].Approved != nil {
+		return "accepted"
+	}
+	return "needs-review"
+}
+
+// statusId returns the transaction id of the overall status of the change. The
+// status only changes with a message, e.g. a vote, a merge or an abandon, so
+// the id of the last message and the current patch set identify it.
+func (c gerritChange) statusId() string {
+	var patchSet int
+	for _, r := range c.Revisions {
+		if r.Number > patchSet {
+			patchSet = r.Number
+		}
+	}
+	if len(c.Messages) == 0 {
+		return fmt.Sprintf("status/ps%d", patchSet)
+	}
+	return fmt.Sprintf("status/%s/ps%d", c.Messages[len(c.Messages)-1].Id, patchSet)
+}
+
+// lastActor returns the user who last commented or voted on the change, or its
+// owner if nobody did
+func (c gerritChange) lastActor() string {
+	if len(c.Messages) == 0 {
+		return c.Owner.user()
+	}
+	return c.Messages[len(c.Messages)-1].Author.user()
+}
+
+// get queries the Gerrit REST API and decodes the JSON response
+func (p *GerritProvider) get(path string, v interface{}) error {
+	url := p.url + path
+	if p.user != "" {
+		// Authenticated requests are prefixed by /a/
+		url = p.url + "/a" + path
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if p.user != "" {
+		req.SetBasicAuth(p.user, p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gerrit request %s failed: %s: %s", path, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	return json.Unmarshal(bytes.TrimPrefix(data, gerritJsonPrefix), v)
+}
+
+func gerritTime(t string) (int64, error) {
+	parsed, err := time.Parse(gerritTimeLayout, t)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Gerrit timestamp '%s'", t)
+	}
+	return parsed.Unix(), nil
+}
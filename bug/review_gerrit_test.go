@@ -0,0 +1,136 @@
+package bug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gerritTestChange = `)]}'
+{
+  "_number": 1234,
+  "subject": "Add the frobnicator",
+  "status": "NEW",
+  "updated": "2026-01-02 10:00:00.000000000",
+  "owner": {"_account_id": 1, "email": "alice@example.com"},
+  "labels": {
+    "Code-Review": {"approved": {"_account_id": 2, "email": "bob@example.com"}}
+  },
+  "messages": [
+    {"id": "m1", "author": {"_account_id": 1, "email": "alice@example.com"}, "date": "2026-01-01 09:00:00.000000000",
+     "message": "Uploaded patch set 1.", "tag": "autogenerated:gerrit:newPatchSet"},
+    {"id": "m2", "author": {"_account_id": 2, "email": "bob@example.com"}, "date": "2026-01-01 11:00:00.000000000",
+     "message": "Patch Set 1: Code-Review-2\n\nPlease add a test.", "_revision_number": 1},
+    {"id": "m3", "author": {"_account_id": 3, "email": "carol@example.com"}, "date": "2026-01-01 12:00:00.000000000",
+     "message": "Patch Set 1: Code-Review-1\n\nMaybe rename it?", "_revision_number": 1},
+    {"id": "m4", "author": {"_account_id": 2, "email": "bob@example.com"}, "date": "2026-01-02 10:00:00.000000000",
+     "message": "Patch Set 2: Code-Review+2", "_revision_number": 2}
+  ],
+  "revisions": {
+    "aaaa": {"_number": 1, "created": "2026-01-01 09:00:00.000000000", "uploader": {"_account_id": 1, "email": "alice@example.com"}},
+    "bbbb": {"_number": 2, "created": "2026-01-01 15:00:00.000000000", "uploader": {"_account_id": 1, "email": "alice@example.com"}}
+  }
+}`
+
+const gerritTestComments = `)]}'
+{
+  "frob.go": [
+    {"id": "c1", "patch_set": 1, "line": 12, "message": "Off by one", "updated": "2026-01-01 11:00:00.000000000",
+     "author": {"_account_id": 2, "email": "bob@example.com"}}
+  ]
+}`
+
+func newGerritTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		if !ok || user != "alice" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/a/changes/1234":
+			_, _ = w.Write([]byte(gerritTestChange))
+		case "/a/changes/1234/comments":
+			_, _ = w.Write([]byte(gerritTestComments))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("Not found: " + r.URL.Path))
+		}
+	}))
+}
+
+func TestGerritProvider_FetchReviewInfo(t *testing.T) {
+	server := newGerritTestServer()
+	defer server.Close()
+
+	provider := NewGerritProvider(server.URL+"/", "alice", "secret", server.Client())
+
+	review, err := provider.FetchReviewInfo("G1234", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "G1234", review.RevisionId)
+	assert.Equal(t, "Add the frobnicator", review.Title)
+	assert.Equal(t, "1767348000", review.LastTransaction)
+	assert.True(t, review.IsAccepted())
+
+	// The advisory -1 vote of carol is only a comment, the -2 of bob requests
+	// changes
+	var got []PhabTransaction
+	for _, u := range review.Updates {
+		got = append(got, u.PhabTransaction)
+	}
+	assert.Equal(t, []PhabTransaction{
+		{TransId: "ps1", PhabUser: "alice@example.com", Timestamp: 1767258000, Type: DiffTransaction, DiffId: 1},
+		{TransId: "c1", PhabUser: "bob@example.com", Timestamp: 1767265200, Type: CommentTransaction,
+			Diff: 1, Path: "frob.go", Line: 12, Text: "Off by one"},
+		{TransId: "m2", PhabUser: "bob@example.com", Timestamp: 1767265200, Type: CommentTransaction, Text: "Please add a test."},
+		{TransId: "m2/ps1", PhabUser: "bob@example.com", Timestamp: 1767265200, Type: UserStatusTransaction, Status: "changes requested"},
+		{TransId: "m3", PhabUser: "carol@example.com", Timestamp: 1767268800, Type: CommentTransaction, Text: "Maybe rename it?"},
+		{TransId: "ps2", PhabUser: "alice@example.com", Timestamp: 1767279600, Type: DiffTransaction, DiffId: 2},
+		{TransId: "status/m4/ps2", PhabUser: "bob@example.com", Timestamp: 1767348000, Type: StatusTransaction, Status: "accepted"},
+		{TransId: "m4/ps2", PhabUser: "bob@example.com", Timestamp: 1767348000, Type: UserStatusTransaction, Status: "accepted"},
+	}, got)
+
+	stored := *review
+
+	// Only the updates at or after the last transaction are returned
+	review, err = provider.FetchReviewInfo("G1234", "1767279600")
+	require.NoError(t, err)
+	assert.Len(t, review.Updates, 3)
+
+	// The updates made at the time of the last transaction are fetched again,
+	// and dropped as they are already stored
+	review, err = provider.FetchReviewInfo("G1234", stored.LastTransaction)
+	require.NoError(t, err)
+	assert.Len(t, review.Updates, 2)
+	review.RemoveKnownUpdates(stored)
+	assert.Empty(t, review.Updates)
+
+	_, err = provider.FetchReviewInfo("G999", "")
+	assert.Error(t, err)
+
+	_, err = NewGerritProvider(server.URL, "alice", "wrong", server.Client()).FetchReviewInfo("G1234", "")
+	assert.Error(t, err)
+	_, err = NewGerritProvider("", "", "", nil).FetchReviewInfo("G1234", "")
+	assert.Error(t, err)
+}
+
+func TestGerritChange_StatusId(t *testing.T) {
+	change := gerritChange{
+		Revisions: map[string]gerritRevision{"aaaa": {Number: 1}},
+	}
+	assert.Equal(t, "status/ps1", change.statusId())
+
+	change.Messages = []gerritMessage{{Id: "m1", RevisionNumber: 1}}
+	assert.Equal(t, "status/m1/ps1", change.statusId())
+
+	// A message made within the same second still gives the new status its
+	// own id, so that it isn't dropped as already known
+	change.Messages = append(change.Messages, gerritMessage{Id: "m2", RevisionNumber: 1})
+	change.Revisions["bbbb"] = gerritRevision{Number: 2}
+	assert.Equal(t, "status/m2/ps2", change.statusId())
+}
@@ -0,0 +1,262 @@
+package bug
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thought-machine/gonduit"
+	"github.com/thought-machine/gonduit/constants"
+	"github.com/thought-machine/gonduit/requests"
+)
+
+// PhabricatorProvider fetches Differential Revisions from Phabricator through
+// the Conduit API
+type PhabricatorProvider struct {
+	dial func() (*gonduit.Conn, error)
+
+	once    sync.Once
+	client  *gonduit.Conn
+	dialErr error
+}
+
+var _ ReviewProvider = &PhabricatorProvider{}
+
+// NewPhabricatorProvider returns a provider connecting to Phabricator with the
+// given function, on first use
+func NewPhabricatorProvider(dial func() (*gonduit.Conn, error)) *PhabricatorProvider {
+	return &PhabricatorProvider{dial: dial}
+}
+
+func (p *PhabricatorProvider) Name() string {
+	return "phabricator"
+}
+
+func (p *PhabricatorProvider) IdPrefix() string {
+	return "D"
+}
+
+// conn returns the connection to Phabricator, shared by the concurrent queries
+func (p *PhabricatorProvider) conn() (*gonduit.Conn, error) {
+	p.once.Do(func() {
+		p.client, p.dialErr = p.dial()
+	})
+	return p.client, p.dialErr
+}
+
+// RevisionRef returns the tag arc pushes the diffs to when the repository has a
+// staging area, e.g. refs/tags/phabricator/diff/42
+func (p *PhabricatorProvider) RevisionRef(id string, diff int) string {
+	return fmt.Sprintf("refs/tags/phabricator/diff/%d", diff)
+}
+
+// statusActionToState maps states returned by Phabricator on to more readable strings
+var statusActionToState = map[string]string{
+	"accept":          "accepted",
+	"close":           "closed",
+	"create":          "created",
+	"request-changes": "changes requested",
+	"request-review":  "review requested",
+}
+
+// FetchReviewInfo exports review comments and status info from Phabricator for
+// the given differential ID and returns in a ReviewInfo struct. If a since
+// transaction ID is specified then only updates since then are returned.
+func (p *PhabricatorProvider) FetchReviewInfo(id string, since string) (*ReviewInfo, error) {
+
+	if matched, _ := regexp.MatchString(`^D\d+$`, id); !matched {
+		return nil, fmt.Errorf("differential id '%s' unexpected format (Dnnn)", id)
+	}
+
+	result := ReviewInfo{RevisionId: id}
+
+	phabClient, err := p.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	var before string
+	var after string
+	var deltaUpdate bool
+
+	// If since is set then only get the transactions since then, else get them all
+	if since != "" {
+		before = since
+		deltaUpdate = true
+	}
+
+	for {
+
+		request := requests.TransactionSearchRequest{ObjectID: id,
+			Before: before,
+			After:  after,
+			Limit:  100}
+
+		response, err := phabClient.TransactionSearch(request)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(response.Data) == 0 {
+			break
+		}
+
+		// If the Cursor.Before field is blank this response includes the latest
+		// transactions, position 0 has the newest
+		if response.Cursor.Before == nil {
+			result.LastTransaction = strconv.Itoa(response.Data[0].ID)
+		}
+
+		// Loop through all transactions
+		for _, t := range response.Data {
+
+			transData := ReviewUpdate{
+				PhabTransaction: PhabTransaction{
+					TransId:   strconv.Itoa(t.ID),
+					PhabUser:  t.AuthorPHID,
+					Timestamp: time.Time(t.DateCreated).Unix()}}
+
+			switch t.Type {
+			// The types: inline & comment hold comments made to a Differential
+
+			case "inline":
+				// If it's an inline comment the Fields contains the file path, line and diff ID
+				diff := t.Fields["diff"].(map[string]interface{})
+				commentDiff := int(diff["id"].(float64))
+				commentPath := t.Fields["path"].(string)
+				commentLine := int(t.Fields["line"].(float64))
+				// Only reported by recent Phabricator versions
+				commentDone, _ := t.Fields["isDone"].(bool)
+
+				transData.Type = CommentTransaction
+
+				for _, c := range t.Comments {
+					transData.Diff = commentDiff
+					transData.Path = commentPath
+					transData.Line = commentLine
+					transData.Done = commentDone
+					transData.Text = c.Content["raw"].(string)
+
+					result.Updates = append(result.Updates, transData)
+				}
+
+			case "comment":
+				transData.Type = CommentTransaction
+
+				for _, c := range t.Comments {
+					transData.Text = c.Content["raw"].(string)
+
+					result.Updates = append(result.Updates, transData)
+				}
+
+			case "status":
+				transData.Type = StatusTransaction
+				transData.Status = t.Fields["new"].(string)
+
+				result.Updates = append(result.Updates, transData)
+
+			case "accept", "close", "create", "request-changes", "request-review":
+				transData.Type = UserStatusTransaction
+				transData.Status = statusActionToState[t.Type]
+
+				result.Updates = append(result.Updates, transData)
+
+			case "title":
+				result.Title = t.Fields["new"].(string)
+
+			case "update":
+				// if it's an update then query Phabricator for the Diff id rather than storing the PHID for it
+				phidDiff := t.Fields["new"].(string)
+				searchConstraint := map[string]interface{}{"phids": [...]string{phidDiff}}
+				request := requests.SearchRequest{Constraints: searchConstraint, Limit: 1}
+
+				response, err := phabClient.DifferentialDiffSearch(request)
+				if err != nil {
+					return nil, err
+				}
+				if len(response.Data) < 1 {
+					return nil, fmt.Errorf("differential %s includes diff %s which gave zero results", id, phidDiff)
+				}
+
+				transData.Type = DiffTransaction
+				transData.DiffId = response.Data[0].ID
+
+				result.Updates = append(result.Updates, transData)
+			}
+		}
+
+		if deltaUpdate {
+			// If we requested only transactions after a certain one (by setting the request
+			// "before" field) then Phabricator sends the oldest transactions first, if there's
+			// more than the "limit" remaining then the Cursor.Before field will be set to
+			// indicate more newer ones are available.
+			if response.Cursor.Before == nil {
+				// there's no more transactions to get
+				break
+			}
+			before = response.Cursor.Before.(string)
+		} else {
+			// If we requested all transactions then Phabricator sends the newest transactions
+			// first, if there's more than the "limit" remaining then the Cursor.After field
+			// will be set to indicate more older ones are available.
+			if response.Cursor.After == nil {
+				// there's no more transactions to get
+				break
+			}
+			after = response.Cursor.After.(string)
+		}
+
+	}
+
+	return &result, nil
+}
+
+// RevisionSummary is the description of a Differential Revision
+type RevisionSummary struct {
+	RevisionId string // e.g. D1234
+	Title      string
+	Summary    string
+}
+
+// SearchRevisions returns the Differential Revisions modified since the given
+// time, the most recently created first
+func (p *PhabricatorProvider) SearchRevisions(modifiedSince time.Time) ([]RevisionSummary, error) {
+	phabClient, err := p.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []RevisionSummary
+	var after string
+
+	for {
+		request := requests.SearchRequest{
+			Constraints: map[string]interface{}{"modifiedStart": modifiedSince.Unix()},
+			Order:       constants.SearchOrderNewest,
+			After:       after,
+			Limit:       100,
+		}
+
+		response, err := phabClient.DifferentialRevisionSearch(request)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range response.Data {
+			title, _ := r.Fields["title"].(string)
+			summary, _ := r.Fields["summary"].(string)
+			result = append(result, RevisionSummary{
+				RevisionId: fmt.Sprintf("%s%d", p.IdPrefix(), r.ID),
+				Title:      title,
+				Summary:    summary,
+			})
+		}
+
+		if response.Cursor.After == "" {
+			return result, nil
+		}
+		after = response.Cursor.After
+	}
+}
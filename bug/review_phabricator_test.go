@@ -0,0 +1,95 @@
+package bug
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thought-machine/gonduit"
+
+	"github.com/daedaleanai/git-ticket/repository"
+)
+
+func newPhabricatorTestProvider(fake *repository.FakeConduit, token string) *PhabricatorProvider {
+	return NewPhabricatorProvider(func() (*gonduit.Conn, error) {
+		return repository.GetPhabClient(fake.URL, token)
+	})
+}
+
+func TestPhabricatorProvider_FetchReviewInfo(t *testing.T) {
+	fake := repository.NewFakeConduit(repository.ConduitFixtures())
+	defer fake.Close()
+
+	// Force the transactions over several pages
+	fake.PageSize = 3
+
+	provider := newPhabricatorTestProvider(fake, repository.FakeConduitToken)
+
+	review, err := provider.FetchReviewInfo("D1234", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "D1234", review.RevisionId)
+	assert.Equal(t, "Add the frobnicator", review.Title)
+	assert.Equal(t, "110", review.LastTransaction)
+	assert.Equal(t, 4, fake.Requests("transaction.search"))
+
+	var got []PhabTransaction
+	for _, u := range review.Updates {
+		got = append(got, u.PhabTransaction)
+	}
+	assert.Equal(t, []PhabTransaction{
+		{TransId: "110", PhabUser: "PHID-USER-bob", Timestamp: 1767348000, Type: StatusTransaction, Status: "accepted"},
+		{TransId: "109", PhabUser: "PHID-USER-bob", Timestamp: 1767348000, Type: UserStatusTransaction, Status: "accepted"},
+		{TransId: "108", PhabUser: "PHID-USER-alice", Timestamp: 1767279600, Type: DiffTransaction, DiffId: 2},
+		{TransId: "107", PhabUser: "PHID-USER-bob", Timestamp: 1767265200, Type: StatusTransaction, Status: "needs-revision"},
+		{TransId: "106", PhabUser: "PHID-USER-bob", Timestamp: 1767265200, Type: UserStatusTransaction, Status: "changes requested"},
+		{TransId: "105", PhabUser: "PHID-USER-bob", Timestamp: 1767265200, Type: CommentTransaction,
+			Diff: 1, Path: "frob.go", Line: 12, Done: true, Text: "Off by one"},
+		{TransId: "104", PhabUser: "PHID-USER-bob", Timestamp: 1767265200, Type: CommentTransaction, Text: "Please add a test."},
+		{TransId: "102", PhabUser: "PHID-USER-alice", Timestamp: 1767258000, Type: DiffTransaction, DiffId: 1},
+		{TransId: "101", PhabUser: "PHID-USER-alice", Timestamp: 1767258000, Type: UserStatusTransaction, Status: "created"},
+	}, got)
+
+	// Only the transactions after the given one are returned, oldest page first
+	review, err = provider.FetchReviewInfo("D1234", "103")
+	require.NoError(t, err)
+	assert.Equal(t, "110", review.LastTransaction)
+	var ids []string
+	for _, u := range review.Updates {
+		ids = append(ids, u.TransId)
+	}
+	assert.Equal(t, []string{"106", "105", "104", "109", "108", "107", "110"}, ids)
+
+	review, err = provider.FetchReviewInfo("D1234", "110")
+	require.NoError(t, err)
+	assert.Empty(t, review.Updates)
+
+	_, err = provider.FetchReviewInfo("D999", "")
+	assert.Error(t, err)
+	_, err = provider.FetchReviewInfo("1234", "")
+	assert.Error(t, err)
+
+	_, err = newPhabricatorTestProvider(fake, "api-wrong").FetchReviewInfo("D1234", "")
+	assert.Error(t, err)
+}
+
+func TestPhabricatorProvider_SearchRevisions(t *testing.T) {
+	fake := repository.NewFakeConduit(repository.ConduitFixtures())
+	defer fake.Close()
+
+	provider := newPhabricatorTestProvider(fake, repository.FakeConduitToken)
+
+	revisions, err := provider.SearchRevisions(time.Unix(1764000000, 0))
+	require.NoError(t, err)
+	assert.Equal(t, []RevisionSummary{
+		{RevisionId: "D1234", Title: "Add the frobnicator", Summary: "Frobnicates the widgets.\n\nTicket: 1234abc"},
+		{RevisionId: "D1200", Title: "Remove the old frobnicator"},
+	}, revisions)
+
+	// Only the revisions modified since the given time are returned
+	revisions, err = provider.SearchRevisions(time.Unix(1767000000, 0))
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, "D1234", revisions[0].RevisionId)
+}
@@ -0,0 +1,121 @@
+package bug
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thought-machine/gonduit"
+
+	"github.com/daedaleanai/git-ticket/repository"
+)
+
+// ReviewProvider fetches the code reviews of a review system and maps their
+// comments, votes and updates on to ReviewInfo updates
+type ReviewProvider interface {
+	// Name returns the name the provider is selected with in the configuration
+	Name() string
+	// IdPrefix returns the prefix of the ids of the reviews handled by the
+	// provider, e.g. "D" for D1234
+	IdPrefix() string
+	// FetchReviewInfo returns the review with the given id. If a since
+	// transaction ID is given then only the updates since then are returned.
+	FetchReviewInfo(id string, since string) (*ReviewInfo, error)
+	// RevisionRef returns the git ref the given diff of the review is fetched
+	// at in the local repository, if it has been fetched
+	RevisionRef(id string, diff int) string
+}
+
+// reviewProviderConfigKey is the git config key selecting the provider of the
+// review ids given without prefix
+const reviewProviderConfigKey = "daedalean.review-provider"
+
+var reviewNumberRegex = regexp.MustCompile(`^\d+$`)
+
+// ReviewProviders selects the provider of a review from the prefix of its id
+type ReviewProviders struct {
+	providers       []ReviewProvider
+	defaultProvider ReviewProvider
+}
+
+// NewReviewProviders returns the providers of the repository. Review ids without
+// prefix are handed to the provider named in the daedalean.review-provider git
+// config, Phabricator if unset. Phabricator is connected to with the given
+// function.
+func NewReviewProviders(repo repository.RepoConfig, phabClient func() (*gonduit.Conn, error)) (*ReviewProviders, error) {
+	gerrit := NewGerritProvider(
+		readReviewConfig(repo, gerritUrlConfigKey),
+		readReviewConfig(repo, gerritUserConfigKey),
+		readReviewConfig(repo, gerritTokenConfigKey),
+		nil)
+
+	return newReviewProviders(readReviewConfig(repo, reviewProviderConfigKey),
+		NewPhabricatorProvider(phabClient), gerrit)
+}
+
+func newReviewProviders(defaultName string, providers ...ReviewProvider) (*ReviewProviders, error) {
+	result := &ReviewProviders{providers: providers}
+
+	if defaultName == "" {
+		result.defaultProvider = providers[0]
+		return result, nil
+	}
+
+	for _, p := range providers {
+		if p.Name() == defaultName {
+			result.defaultProvider = p
+			return result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown review provider %q in %s", defaultName, reviewProviderConfigKey)
+}
+
+// Resolve returns the provider of the review with the given id, along with the
+// full id of the review
+func (p *ReviewProviders) Resolve(id string) (ReviewProvider, string, error) {
+	if reviewNumberRegex.MatchString(id) {
+		return p.defaultProvider, p.defaultProvider.IdPrefix() + id, nil
+	}
+
+	for _, provider := range p.providers {
+		prefix := provider.IdPrefix()
+		if strings.HasPrefix(id, prefix) && reviewNumberRegex.MatchString(id[len(prefix):]) {
+			return provider, id, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("review id '%s' doesn't match any review provider", id)
+}
+
+// FetchReviewInfo returns the review with the given id from its provider. If a
+// since transaction ID is given then only the updates since then are returned.
+func (p *ReviewProviders) FetchReviewInfo(id string, since string) (*ReviewInfo, error) {
+	provider, id, err := p.Resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	return provider.FetchReviewInfo(id, since)
+}
+
+// RevisionRef returns the git ref the given diff of the review is fetched at
+// in the local repository
+func (p *ReviewProviders) RevisionRef(id string, diff int) (string, error) {
+	provider, id, err := p.Resolve(id)
+	if err != nil {
+		return "", err
+	}
+	return provider.RevisionRef(id, diff), nil
+}
+
+// readReviewConfig returns the value of a key of the repository config, or of
+// the global config if it's not set in the repository
+func readReviewConfig(repo repository.RepoConfig, key string) string {
+	if value, err := repo.LocalConfig().ReadString(key); err == nil {
+		return value
+	}
+	if value, err := repo.GlobalConfig().ReadString(key); err == nil {
+		return value
+	}
+	return ""
+}
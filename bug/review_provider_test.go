@@ -0,0 +1,65 @@
+package bug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewProviders_Resolve(t *testing.T) {
+	phabricator := NewPhabricatorProvider(nil)
+	gerrit := NewGerritProvider("", "", "", nil)
+
+	providers, err := newReviewProviders("", phabricator, gerrit)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		id       string
+		provider ReviewProvider
+		fullId   string
+	}{
+		{"D1234", phabricator, "D1234"},
+		{"G1234", gerrit, "G1234"},
+		{"1234", phabricator, "D1234"},
+	} {
+		provider, fullId, err := providers.Resolve(tc.id)
+		assert.NoError(t, err, tc.id)
+		assert.Equal(t, tc.provider, provider, tc.id)
+		assert.Equal(t, tc.fullId, fullId, tc.id)
+	}
+
+	for _, id := range []string{"", "D", "X1234", "D12a", "G-1"} {
+		_, _, err := providers.Resolve(id)
+		assert.Error(t, err, id)
+	}
+
+	providers, err = newReviewProviders("gerrit", phabricator, gerrit)
+	require.NoError(t, err)
+	provider, fullId, err := providers.Resolve("1234")
+	assert.NoError(t, err)
+	assert.Equal(t, gerrit, provider)
+	assert.Equal(t, "G1234", fullId)
+
+	_, err = newReviewProviders("gitlab", phabricator, gerrit)
+	assert.Error(t, err)
+}
+
+func TestReviewProviders_RevisionRef(t *testing.T) {
+	providers, err := newReviewProviders("", NewPhabricatorProvider(nil), NewGerritProvider("", "", "", nil))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		id   string
+		diff int
+		ref  string
+	}{
+		{"D1234", 42, "refs/tags/phabricator/diff/42"},
+		{"G1234", 2, "refs/changes/34/1234/2"},
+		{"G5", 1, "refs/changes/05/5/1"},
+	} {
+		ref, err := providers.RevisionRef(tc.id, tc.diff)
+		assert.NoError(t, err, tc.id)
+		assert.Equal(t, tc.ref, ref, tc.id)
+	}
+}
@@ -3,15 +3,10 @@ package bug
 import (
 	"encoding/json"
 	"fmt"
-	"regexp"
-	"strconv"
+	"sort"
 	"strings"
-	"time"
-
-	"github.com/thought-machine/gonduit/requests"
 
 	"github.com/daedaleanai/git-ticket/identity"
-	"github.com/daedaleanai/git-ticket/repository"
 )
 
 type TransactionType int
@@ -24,10 +19,11 @@ const (
 	DiffTransaction
 )
 
-// PhapTransaction holds data received from Phabricator
+// PhapTransaction holds data received from Phabricator, or from another review
+// provider mapped on to the same transactions
 type PhabTransaction struct {
 	TransId   string
-	PhabUser  string
+	PhabUser  string // the PHID of the user, or their email for providers other than Phabricator
 	Timestamp int64
 
 	Type TransactionType
@@ -35,6 +31,7 @@ type PhabTransaction struct {
 	Diff int    `json:",omitempty"` // diff id comment was made againt, inline comments only
 	Path string `json:",omitempty"` // file path, inline comments only
 	Line int    `json:",omitempty"` // line number, inline comments only
+	Done bool   `json:",omitempty"` // comment marked done or resolved, inline comments only
 	Text string `json:",omitempty"`
 	// status and userstatus specific fields
 	Status string `json:",omitempty"`
@@ -50,22 +47,89 @@ type ReviewUpdate struct {
 
 // ReviewInfo holds a set of comment and status updates related to a diff
 type ReviewInfo struct {
-	RevisionId      string // e.g. D1234
+	RevisionId      string // e.g. D1234, prefixed by the provider
 	Title           string
 	LastTransaction string
 	Updates         []ReviewUpdate
 }
 
-// statusActionToState maps states returned by Phabricator on to more readable strings
-var statusActionToState = map[string]string{
-	"accept":          "accepted",
-	"close":           "closed",
-	"create":          "created",
-	"request-changes": "changes requested",
-	"request-review":  "review requested",
+const RemoveReviewInfo = "-1"
+
+// RemoveKnownUpdates drops the updates which are already in the stored review.
+// Providers whose last transaction is a time return the updates made at that
+// time again, as more may have been made within the same second.
+func (r *ReviewInfo) RemoveKnownUpdates(stored ReviewInfo) {
+	known := make(map[string]bool)
+	for _, u := range stored.Updates {
+		known[u.TransId] = true
+	}
+
+	updates := r.Updates[:0]
+	for _, u := range r.Updates {
+		if !known[u.TransId] {
+			updates = append(updates, u)
+		}
+	}
+	r.Updates = updates
 }
 
-const RemoveReviewInfo = "-1"
+// ReviewThread is a set of inline comments made on the same line of a diff
+type ReviewThread struct {
+	Diff     int
+	Path     string
+	Line     int
+	Comments []ReviewUpdate
+}
+
+// Resolved returns true if the last comment of the thread was marked done
+func (t ReviewThread) Resolved() bool {
+	return len(t.Comments) > 0 && t.Comments[len(t.Comments)-1].Done
+}
+
+// InlineThreads returns the inline comments of the review grouped in threads,
+// ordered by file, line and diff. The comments of a thread are in chronological
+// order.
+func (r ReviewInfo) InlineThreads() []ReviewThread {
+	type threadKey struct {
+		diff int
+		path string
+		line int
+	}
+
+	threads := make(map[threadKey]*ReviewThread)
+	var keys []threadKey
+
+	for _, u := range r.Updates {
+		if u.Type != CommentTransaction || u.Path == "" {
+			continue
+		}
+		key := threadKey{diff: u.Diff, path: u.Path, line: u.Line}
+		if _, ok := threads[key]; !ok {
+			threads[key] = &ReviewThread{Diff: u.Diff, Path: u.Path, Line: u.Line}
+			keys = append(keys, key)
+		}
+		threads[key].Comments = append(threads[key].Comments, u)
+	}
+
+	result := make([]ReviewThread, 0, len(keys))
+	for _, k := range keys {
+		t := threads[k]
+		sort.SliceStable(t.Comments, func(i, j int) bool { return t.Comments[i].Timestamp < t.Comments[j].Timestamp })
+		result = append(result, *t)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		if result[i].Line != result[j].Line {
+			return result[i].Line < result[j].Line
+		}
+		return result[i].Diff < result[j].Diff
+	})
+
+	return result
+}
 
 // OneLineComment returns a string containing the comment text, and it's an inline
 // comment the file & line details, on a single line. Comments over 50 characters
@@ -132,169 +196,69 @@ func (r ReviewInfo) LatestOverallStatus() string {
 	return ls.Status
 }
 
-// LatestUserStatuses returns a map of users and the latest status they set for
-// this review.
-func (r ReviewInfo) LatestUserStatuses() map[string]ReviewUpdate {
-	// Create a map of the latest status change made by all users
-	userStatusChange := make(map[string]ReviewUpdate)
+// IsAccepted returns true if the latest overall status of the review is accepted
+func (r ReviewInfo) IsAccepted() bool {
+	return r.LatestOverallStatus() == reviewAcceptedStatus
+}
 
-	for _, s := range r.Updates {
-		if s.Type != UserStatusTransaction {
+// ReviewStatuses are the overall statuses of the reviews, as set by Phabricator
+// or mapped from the state of the Gerrit changes
+var ReviewStatuses = []string{
+	"draft", "needs-review", "needs-revision", "changes-planned", "accepted", "published", "abandoned", "closed",
+}
+
+// Reviewers returns the first update of each user who accepted the review,
+// requested changes or commented on it, in the order of the updates
+func (r ReviewInfo) Reviewers() []ReviewUpdate {
+	seen := make(map[string]bool)
+	var result []ReviewUpdate
+
+	for _, u := range r.Updates {
+		switch {
+		case u.Type == CommentTransaction:
+		case u.Type == UserStatusTransaction && (u.Status == "accepted" || u.Status == "changes requested"):
+		default:
 			continue
 		}
-		if sc, present := userStatusChange[s.PhabUser]; !present || s.Timestamp > sc.Timestamp {
-			userStatusChange[s.PhabUser] = s
+		if seen[u.PhabUser] {
+			continue
 		}
+		seen[u.PhabUser] = true
+		result = append(result, u)
 	}
 
-	return userStatusChange
+	return result
 }
 
-// FetchReviewInfo exports review comments and status info from Phabricator for
-// the given differential ID and returns in a ReviewInfo struct. If a since
-// transaction ID is specified then only updates since then are returned.
-func FetchReviewInfo(id string, since string) (*ReviewInfo, error) {
-
-	if matched, _ := regexp.MatchString(`^D\d+$`, id); !matched {
-		return nil, fmt.Errorf("differential id '%s' unexpected format (Dnnn)", id)
-	}
-
-	result := ReviewInfo{RevisionId: id}
-
-	phabClient, err := repository.GetPhabClient()
-	if err != nil {
-		return nil, err
-	}
-
-	var before string
-	var after string
-	var deltaUpdate bool
-
-	// If since is set then only get the transactions since then, else get them all
-	if since != "" {
-		before = since
-		deltaUpdate = true
-	}
-
-	for {
-
-		request := requests.TransactionSearchRequest{ObjectID: id,
-			Before: before,
-			After:  after,
-			Limit:  100}
-
-		response, err := phabClient.TransactionSearch(request)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(response.Data) == 0 {
-			break
-		}
+// closedReviewStatuses are the overall Phabricator statuses of reviews which
+// won't change anymore
+var closedReviewStatuses = []string{"published", "abandoned", "closed"}
 
-		// If the Cursor.Before field is blank this response includes the latest
-		// transactions, position 0 has the newest
-		if response.Cursor.Before == nil {
-			result.LastTransaction = strconv.Itoa(response.Data[0].ID)
+// IsClosed returns true if the review has been published or abandoned
+func (r ReviewInfo) IsClosed() bool {
+	status := r.LatestOverallStatus()
+	for _, s := range closedReviewStatuses {
+		if status == s {
+			return true
 		}
+	}
+	return false
+}
 
-		// Loop through all transactions
-		for _, t := range response.Data {
-
-			transData := ReviewUpdate{
-				PhabTransaction: PhabTransaction{
-					TransId:   strconv.Itoa(t.ID),
-					PhabUser:  t.AuthorPHID,
-					Timestamp: time.Time(t.DateCreated).Unix()}}
-
-			switch t.Type {
-			// The types: inline & comment hold comments made to a Differential
-
-			case "inline":
-				// If it's an inline comment the Fields contains the file path, line and diff ID
-				diff := t.Fields["diff"].(map[string]interface{})
-				commentDiff := int(diff["id"].(float64))
-				commentPath := t.Fields["path"].(string)
-				commentLine := int(t.Fields["line"].(float64))
-
-				transData.Type = CommentTransaction
-
-				for _, c := range t.Comments {
-					transData.Diff = commentDiff
-					transData.Path = commentPath
-					transData.Line = commentLine
-					transData.Text = c.Content["raw"].(string)
-
-					result.Updates = append(result.Updates, transData)
-				}
-
-			case "comment":
-				transData.Type = CommentTransaction
-
-				for _, c := range t.Comments {
-					transData.Text = c.Content["raw"].(string)
-
-					result.Updates = append(result.Updates, transData)
-				}
-
-			case "status":
-				transData.Type = StatusTransaction
-				transData.Status = t.Fields["new"].(string)
-
-				result.Updates = append(result.Updates, transData)
-
-			case "accept", "close", "create", "request-changes", "request-review":
-				transData.Type = UserStatusTransaction
-				transData.Status = statusActionToState[t.Type]
-
-				result.Updates = append(result.Updates, transData)
-
-			case "title":
-				result.Title = t.Fields["new"].(string)
-
-			case "update":
-				// if it's an update then query Phabricator for the Diff id rather than storing the PHID for it
-				phidDiff := t.Fields["new"].(string)
-				searchConstraint := map[string]interface{}{"phids": [...]string{phidDiff}}
-				request := requests.SearchRequest{Constraints: searchConstraint, Limit: 1}
-
-				response, err := phabClient.DifferentialDiffSearch(request)
-				if err != nil {
-					return nil, err
-				}
-				if len(response.Data) < 1 {
-					return nil, fmt.Errorf("differential %s includes diff %s which gave zero results", id, phidDiff)
-				}
-
-				transData.Type = DiffTransaction
-				transData.DiffId = response.Data[0].ID
+// LatestUserStatuses returns a map of users and the latest status they set for
+// this review.
+func (r ReviewInfo) LatestUserStatuses() map[string]ReviewUpdate {
+	// Create a map of the latest status change made by all users
+	userStatusChange := make(map[string]ReviewUpdate)
 
-				result.Updates = append(result.Updates, transData)
-			}
+	for _, s := range r.Updates {
+		if s.Type != UserStatusTransaction {
+			continue
 		}
-
-		if deltaUpdate {
-			// If we requested only transactions after a certain one (by setting the request
-			// "before" field) then Phabricator sends the oldest transactions first, if there's
-			// more than the "limit" remaining then the Cursor.Before field will be set to
-			// indicate more newer ones are available.
-			if response.Cursor.Before == nil {
-				// there's no more transactions to get
-				break
-			}
-			before = response.Cursor.Before.(string)
-		} else {
-			// If we requested all transactions then Phabricator sends the newest transactions
-			// first, if there's more than the "limit" remaining then the Cursor.After field
-			// will be set to indicate more older ones are available.
-			if response.Cursor.After == nil {
-				// there's no more transactions to get
-				break
-			}
-			after = response.Cursor.After.(string)
+		if sc, present := userStatusChange[s.PhabUser]; !present || s.Timestamp > sc.Timestamp {
+			userStatusChange[s.PhabUser] = s
 		}
-
 	}
 
-	return &result, nil
+	return userStatusChange
 }
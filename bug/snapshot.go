@@ -136,7 +136,7 @@ func (snap *Snapshot) HasAnyActor(ids ...entity.Id) bool {
 func (snap *Snapshot) IsAuthored() {}
 
 // GetUserChecklists returns a map of checklists associated with this snapshot for the given reviewer id
-func (snap *Snapshot) GetUserChecklists(reviewer entity.Id) (map[Label]Checklist, error) {
+func (snap *Snapshot) GetUserChecklists(configs *ConfigCache, reviewer entity.Id) (map[Label]Checklist, error) {
 	checklists := make(map[Label]Checklist)
 
 	// Only checklists named in the labels list are currently valid
@@ -146,7 +146,7 @@ func (snap *Snapshot) GetUserChecklists(reviewer entity.Id) (map[Label]Checklist
 				checklists[l] = snapshotChecklist.Checklist
 			} else {
 				var err error
-				checklists[l], err = GetChecklist(l)
+				checklists[l], err = configs.Checklist(l)
 				if err != nil {
 					return nil, err
 				}
@@ -188,31 +188,38 @@ func (snap *Snapshot) GetChecklistCompoundStates() map[Label]ChecklistState {
 	return states
 }
 
-// NextStates returns a slice of next possible states for the assigned workflow
-func (snap *Snapshot) NextStates() ([]Status, error) {
+// Workflow returns the workflow assigned to the ticket
+func (snap *Snapshot) Workflow(configs *ConfigCache) (*Workflow, error) {
 	for _, l := range snap.Labels {
 		if l.IsWorkflow() {
-			w := FindWorkflow(l)
+			w := configs.FindWorkflow(l)
 			if w == nil {
 				return nil, fmt.Errorf("invalid workflow %s", l)
 			}
-			return w.NextStates(snap.Status)
+			return w, nil
 		}
 	}
 	return nil, fmt.Errorf("ticket has no associated workflow")
 }
 
-// ValidateTransition returns an error if the supplied state is an invalid
-// destination from the current state for the assigned workflow
-func (snap *Snapshot) ValidateTransition(newStatus Status) error {
-	for _, l := range snap.Labels {
-		if l.IsWorkflow() {
-			w := FindWorkflow(l)
-			if w == nil {
-				return fmt.Errorf("invalid workflow %s", l)
-			}
-			return w.ValidateTransition(snap.Status, newStatus)
-		}
+// NextStates returns a slice of next possible states for the assigned workflow
+func (snap *Snapshot) NextStates(configs *ConfigCache) ([]Status, error) {
+	w, err := snap.Workflow(configs)
+	if err != nil {
+		return nil, err
+	}
+	return w.NextStates(snap.Status)
+}
+
+// ValidateTransition returns an error if the state of the context is an invalid
+// destination from the current state for the assigned workflow, if the
+// snapshot doesn't meet the transition guards or if the transition hook vetoes it
+func (snap *Snapshot) ValidateTransition(ctx *TransitionContext) error {
+	ctx.Snapshot = snap
+
+	w, err := snap.Workflow(ctx.Configs)
+	if err != nil {
+		return err
 	}
-	return fmt.Errorf("ticket has no associated workflow")
+	return w.ValidateSnapshotTransition(ctx)
 }
@@ -1,103 +1,128 @@
 package bug
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 )
 
-type Status int
+// Status is the name of a state a ticket can be in. A set of built-in statuses
+// is always available, workflows can add their own.
+type Status string
 
 const (
-	_ Status = iota
-	ProposedStatus
-	VettedStatus
-	InProgressStatus
-	InReviewStatus
-	ReviewedStatus
-	AcceptedStatus
-	MergedStatus
-	DoneStatus
+	ProposedStatus   Status = "proposed"
+	VettedStatus     Status = "vetted"
+	InProgressStatus Status = "inprogress"
+	InReviewStatus   Status = "inreview"
+	ReviewedStatus   Status = "reviewed"
+	AcceptedStatus   Status = "accepted"
+	MergedStatus     Status = "merged"
+	DoneStatus       Status = "done"
 )
 
-const FirstStatus = ProposedStatus
-const LastStatus = DoneStatus
-const NumStatuses = 8
+// builtinStatuses lists the built-in statuses, in the order they were numbered
+// when statuses were stored in operations as integers
+var builtinStatuses = []Status{
+	ProposedStatus,
+	VettedStatus,
+	InProgressStatus,
+	InReviewStatus,
+	ReviewedStatus,
+	AcceptedStatus,
+	MergedStatus,
+	DoneStatus,
+}
+
+// BuiltinStatuses returns the built-in statuses, the ones available in every
+// repository
+func BuiltinStatuses() []Status {
+	statuses := make([]Status, len(builtinStatuses))
+	copy(statuses, builtinStatuses)
+	return statuses
+}
+
+// builtinActions holds the action text of the built-in statuses
+var builtinActions = map[Status]string{
+	ProposedStatus:   "set PROPOSED",
+	VettedStatus:     "set VETTED",
+	InProgressStatus: "set IN PROGRESS",
+	InReviewStatus:   "set IN REVIEW",
+	ReviewedStatus:   "set REVIEWED",
+	AcceptedStatus:   "set ACCEPTED",
+	MergedStatus:     "set MERGED",
+	DoneStatus:       "set DONE",
+}
+
+var statusNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
 
 func (s Status) String() string {
-	switch s {
-	case ProposedStatus:
-		return "proposed"
-	case VettedStatus:
-		return "vetted"
-	case InProgressStatus:
-		return "inprogress"
-	case InReviewStatus:
-		return "inreview"
-	case ReviewedStatus:
-		return "reviewed"
-	case AcceptedStatus:
-		return "accepted"
-	case MergedStatus:
-		return "merged"
-	case DoneStatus:
-		return "done"
-	default:
+	if s == "" {
 		return "unknown status"
 	}
+	return string(s)
 }
 
 func (s Status) Action() string {
-	switch s {
-	case ProposedStatus:
-		return "set PROPOSED"
-	case VettedStatus:
-		return "set VETTED"
-	case InProgressStatus:
-		return "set IN PROGRESS"
-	case InReviewStatus:
-		return "set IN REVIEW"
-	case ReviewedStatus:
-		return "set REVIEWED"
-	case AcceptedStatus:
-		return "set ACCEPTED"
-	case MergedStatus:
-		return "set MERGED"
-	case DoneStatus:
-		return "set DONE"
-	default:
-		return "unknown status"
+	if action, ok := builtinActions[s]; ok {
+		return action
 	}
+	return "set " + strings.ToUpper(string(s))
 }
 
-func StatusFromString(str string) (Status, error) {
-	cleaned := strings.ToLower(strings.TrimSpace(str))
-
-	switch cleaned {
-	case "proposed":
-		return ProposedStatus, nil
-	case "vetted":
-		return VettedStatus, nil
-	case "inprogress":
-		return InProgressStatus, nil
-	case "inreview":
-		return InReviewStatus, nil
-	case "reviewed":
-		return ReviewedStatus, nil
-	case "accepted":
-		return AcceptedStatus, nil
-	case "merged":
-		return MergedStatus, nil
-	case "done":
-		return DoneStatus, nil
-	default:
-		return 0, fmt.Errorf("unknown status: %s", cleaned)
+// ParseStatus returns a status with the given name, it only checks the name is
+// well formed, not that the status is known. Use ConfigCache.StatusFromString
+// for that.
+func ParseStatus(str string) (Status, error) {
+	s := Status(strings.ToLower(strings.TrimSpace(str)))
+
+	if err := s.Validate(); err != nil {
+		return "", fmt.Errorf("invalid status name: %q", str)
 	}
+
+	return s, nil
 }
 
 func (s Status) Validate() error {
-	if s < FirstStatus || s > LastStatus {
+	if !statusNameRegexp.MatchString(string(s)) {
 		return fmt.Errorf("invalid")
 	}
 
 	return nil
 }
+
+// UnmarshalJSON fulfils the Unmarshaler interface so that operations written
+// before statuses were stored by name, as the index of a built-in status, can
+// still be read
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var index int
+	if err := json.Unmarshal(data, &index); err == nil {
+		if index >= 1 && index <= len(builtinStatuses) {
+			*s = builtinStatuses[index-1]
+		} else {
+			// leave it to Validate to reject
+			*s = ""
+		}
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	*s = Status(name)
+
+	return nil
+}
+
+func statusExist(statuses []Status, status Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,16 +1,21 @@
 package bug
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
+	"sort"
 	"strings"
+	"time"
 )
 
 type Transition struct {
-	start Status
-	end   Status
-	hook  string
+	start       Status
+	end         Status
+	hook        string
+	hookTimeout time.Duration
+	guards      []Guard
+	allowed     *Permission
+	trigger     EventType
 }
 
 type Workflow struct {
@@ -19,25 +24,169 @@ type Workflow struct {
 	transitions  []Transition
 }
 
-var workflowStore []Workflow
+// transitionConfig is the JSON representation of a Transition
+type transitionConfig struct {
+	Start       string      `json:"start"`
+	End         string      `json:"end"`
+	Hook        string      `json:"hook,omitempty"`
+	HookTimeout string      `json:"hookTimeout,omitempty"`
+	Guards      []Guard     `json:"guards,omitempty"`
+	Allowed     *Permission `json:"allowed,omitempty"`
+	Trigger     EventType   `json:"trigger,omitempty"`
+}
+
+// workflowConfig is the JSON representation of a Workflow, the label is the
+// key the workflow is stored under in the configuration
+type workflowConfig struct {
+	InitialState string             `json:"initialState"`
+	Transitions  []transitionConfig `json:"transitions"`
+}
+
+// defaultWorkflows are used when the repository doesn't hold a workflows configuration
+var defaultWorkflows = []Workflow{
+	Workflow{label: "workflow:eng",
+		initialState: ProposedStatus,
+		transitions: []Transition{
+			Transition{start: ProposedStatus, end: VettedStatus},
+			Transition{start: VettedStatus, end: ProposedStatus},
+			Transition{start: VettedStatus, end: InProgressStatus},
+			Transition{start: InProgressStatus, end: InReviewStatus},
+			Transition{start: InReviewStatus, end: InProgressStatus},
+			Transition{start: InReviewStatus, end: ReviewedStatus},
+			Transition{start: ReviewedStatus, end: AcceptedStatus},
+			Transition{start: AcceptedStatus, end: MergedStatus},
+		},
+	},
+	Workflow{label: "workflow:qa",
+		initialState: ProposedStatus,
+		transitions: []Transition{
+			Transition{start: ProposedStatus, end: InProgressStatus},
+			Transition{start: InProgressStatus, end: DoneStatus},
+		},
+	},
+}
 
-// FindWorkflow searches the workflow store by name and returns a pointer to it
-func FindWorkflow(name Label) *Workflow {
-	for wf := range workflowStore {
-		if workflowStore[wf].label == name {
-			return &workflowStore[wf]
+// ParseWorkflows decodes and validates a JSON workflows configuration, a map
+// of workflow label to workflow definition
+func ParseWorkflows(data []byte) ([]Workflow, error) {
+	workflowMap := make(map[Label]Workflow)
+
+	if err := json.Unmarshal(data, &workflowMap); err != nil {
+		return nil, err
+	}
+
+	workflows := make([]Workflow, 0, len(workflowMap))
+	for label, wf := range workflowMap {
+		wf.label = label
+		if err := wf.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %s", label, err)
 		}
+		workflows = append(workflows, wf)
 	}
-	return nil
+
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].label < workflows[j].label
+	})
+
+	return workflows, nil
+}
+
+// Validate checks the workflow is well formed
+func (w *Workflow) Validate() error {
+	if !w.label.IsWorkflow() {
+		return fmt.Errorf("label must start with \"workflow:\"")
+	}
+
+	if err := w.initialState.Validate(); err != nil {
+		return fmt.Errorf("invalid initial state")
+	}
+
+	for i, t := range w.transitions {
+		if err := t.start.Validate(); err != nil {
+			return fmt.Errorf("transition %d: invalid start state", i)
+		}
+		if err := t.end.Validate(); err != nil {
+			return fmt.Errorf("transition %d: invalid end state", i)
+		}
+		if t.hook != "" {
+			if _, err := splitHookCommand(t.hook); err != nil {
+				return fmt.Errorf("transition %s -> %s: invalid hook: %s", t.start, t.end, err)
+			}
+		}
+		if t.hookTimeout < 0 {
+			return fmt.Errorf("transition %s -> %s: negative hook timeout", t.start, t.end)
+		}
+		for _, g := range t.guards {
+			if err := g.Validate(); err != nil {
+				return fmt.Errorf("transition %s -> %s: %s", t.start, t.end, err)
+			}
+		}
+		if t.allowed != nil {
+			if err := t.allowed.Validate(); err != nil {
+				return fmt.Errorf("transition %s -> %s: %s", t.start, t.end, err)
+			}
+		}
+		if t.trigger != "" {
+			if err := t.trigger.Validate(); err != nil {
+				return fmt.Errorf("transition %s -> %s: %s", t.start, t.end, err)
+			}
+		}
+		for _, other := range w.transitions[:i] {
+			if other.start == t.start && other.end == t.end {
+				return fmt.Errorf("duplicate transition %s -> %s", t.start, t.end)
+			}
+			if t.trigger != "" && other.start == t.start && other.trigger == t.trigger {
+				return fmt.Errorf("transitions %s -> %s and %s -> %s are both triggered by %s",
+					other.start, other.end, t.start, t.end, t.trigger)
+			}
+		}
+	}
+
+	return w.validateTriggers()
+}
+
+// Label returns the label selecting the workflow
+func (w *Workflow) Label() Label {
+	return w.label
+}
+
+// InitialState returns the state a ticket is put in when the workflow is assigned
+func (w *Workflow) InitialState() Status {
+	return w.initialState
 }
 
-// GetWorkflowLabels returns a slice of all the available workflow labels
-func GetWorkflowLabels() []Label {
-	var labels []Label
-	for _, wf := range workflowStore {
-		labels = append(labels, wf.label)
+// Transitions returns the transitions allowed by the workflow
+func (w *Workflow) Transitions() []Transition {
+	return w.transitions
+}
+
+// States returns all the states used in the workflow, starting with the
+// initial state
+func (w *Workflow) States() []Status {
+	states := []Status{w.initialState}
+	for _, t := range w.transitions {
+		if !statusExist(states, t.start) {
+			states = append(states, t.start)
+		}
+		if !statusExist(states, t.end) {
+			states = append(states, t.end)
+		}
 	}
-	return labels
+	return states
+}
+
+// ReachableStates returns the states a ticket can get in through the
+// transitions of the workflow, starting with the initial state
+func (w *Workflow) ReachableStates() []Status {
+	states := []Status{w.initialState}
+	for i := 0; i < len(states); i++ {
+		for _, t := range w.transitions {
+			if t.start == states[i] && !statusExist(states, t.end) {
+				states = append(states, t.end)
+			}
+		}
+	}
+	return states
 }
 
 // NextStates returns a slice of next possible states in the workflow
@@ -52,45 +201,149 @@ func (w *Workflow) NextStates(s Status) ([]Status, error) {
 	return validStates, nil
 }
 
+// findTransition returns the transition for a given start and end, or nil
+// if there isn't one
+func (w *Workflow) findTransition(from, to Status) *Transition {
+	for i := range w.transitions {
+		if w.transitions[i].start == from && w.transitions[i].end == to {
+			return &w.transitions[i]
+		}
+	}
+	return nil
+}
+
 // ValidateTransition checks if the transition is valid for a given start and end
 func (w *Workflow) ValidateTransition(from, to Status) error {
-	for _, t := range w.transitions {
-		if t.start == from && t.end == to {
-			if t.hook != "" {
-				hookArgs := strings.Split(t.hook, " ")
-				cmd := exec.Command(hookArgs[0], hookArgs[1:]...)
-				cmd.Stdout = os.Stdout
-				cmd.Stderr = os.Stderr
-				return cmd.Run()
+	t := w.findTransition(from, to)
+	if t == nil {
+		return fmt.Errorf("invalid transition %s -> %s", from, to)
+	}
+	return t.runHook(&TransitionContext{Snapshot: &Snapshot{Status: from}, To: to})
+}
+
+// ValidateSnapshotTransition checks if the transition described by the context
+// is valid, including the transition permission, guards and hook
+func (w *Workflow) ValidateSnapshotTransition(ctx *TransitionContext) error {
+	t := w.findTransition(ctx.Snapshot.Status, ctx.To)
+	if t == nil {
+		return fmt.Errorf("invalid transition %s -> %s", ctx.Snapshot.Status, ctx.To)
+	}
+	if err := t.CheckPermission(ctx.Actor, ctx.Configs.Groups()); err != nil {
+		return err
+	}
+	if err := t.CheckGuards(ctx.Snapshot); err != nil {
+		return err
+	}
+	return t.runHook(ctx)
+}
+
+// Start returns the state the transition leaves
+func (t *Transition) Start() Status {
+	return t.start
+}
+
+// End returns the state the transition enters
+func (t *Transition) End() Status {
+	return t.end
+}
+
+// Hook returns the command run before the transition is accepted, if any
+func (t *Transition) Hook() string {
+	return t.hook
+}
+
+// HookTimeout returns how long the hook may run
+func (t *Transition) HookTimeout() time.Duration {
+	if t.hookTimeout == 0 {
+		return defaultHookTimeout
+	}
+	return t.hookTimeout
+}
+
+// Guards returns the conditions to meet before the transition is allowed
+func (t *Transition) Guards() []Guard {
+	return t.guards
+}
+
+// CheckGuards returns an error listing every guard of the transition the
+// snapshot doesn't meet
+func (t *Transition) CheckGuards(snap *Snapshot) error {
+	var failed []string
+	for _, g := range t.guards {
+		if err := g.Check(snap); err != nil {
+			failed = append(failed, fmt.Sprintf("[%s] %s", g.Type, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("transition %s -> %s not allowed:\n%s", t.start, t.end, strings.Join(failed, "\n"))
+	}
+
+	return nil
+}
+
+// MarshalJSON fulfils the Marshaler interface so that the workflow can be
+// stored as configuration with the states written by name
+func (w Workflow) MarshalJSON() ([]byte, error) {
+	wc := workflowConfig{
+		InitialState: w.initialState.String(),
+		Transitions:  make([]transitionConfig, len(w.transitions)),
+	}
+
+	for i, t := range w.transitions {
+		wc.Transitions[i] = transitionConfig{
+			Start:   t.start.String(),
+			End:     t.end.String(),
+			Hook:    t.hook,
+			Guards:  t.guards,
+			Allowed: t.allowed,
+			Trigger: t.trigger,
+		}
+		if t.hookTimeout != 0 {
+			wc.Transitions[i].HookTimeout = t.hookTimeout.String()
+		}
+	}
+
+	return json.Marshal(wc)
+}
+
+// UnmarshalJSON fulfils the Unmarshaler interface so that the workflow can be
+// read from the configuration
+func (w *Workflow) UnmarshalJSON(data []byte) error {
+	var wc workflowConfig
+
+	err := json.Unmarshal(data, &wc)
+	if err != nil {
+		return err
+	}
+
+	initialState, err := ParseStatus(wc.InitialState)
+	if err != nil {
+		return fmt.Errorf("initial state: %s", err)
+	}
+
+	transitions := make([]Transition, len(wc.Transitions))
+	for i, tc := range wc.Transitions {
+		start, err := ParseStatus(tc.Start)
+		if err != nil {
+			return fmt.Errorf("transition %d: %s", i, err)
+		}
+		end, err := ParseStatus(tc.End)
+		if err != nil {
+			return fmt.Errorf("transition %d: %s", i, err)
+		}
+		var hookTimeout time.Duration
+		if tc.HookTimeout != "" {
+			hookTimeout, err = time.ParseDuration(tc.HookTimeout)
+			if err != nil {
+				return fmt.Errorf("transition %d: invalid hook timeout: %s", i, err)
 			}
-			return nil
-		}
-	}
-	return fmt.Errorf("invalid transition %s -> %s", from, to)
-}
-
-func init() {
-	// Initialise list of workflows
-	workflowStore = []Workflow{
-		Workflow{label: "workflow:eng",
-			initialState: ProposedStatus,
-			transitions: []Transition{
-				Transition{start: ProposedStatus, end: VettedStatus},
-				Transition{start: VettedStatus, end: ProposedStatus},
-				Transition{start: VettedStatus, end: InProgressStatus},
-				Transition{start: InProgressStatus, end: InReviewStatus},
-				Transition{start: InReviewStatus, end: InProgressStatus},
-				Transition{start: InReviewStatus, end: ReviewedStatus},
-				Transition{start: ReviewedStatus, end: AcceptedStatus},
-				Transition{start: AcceptedStatus, end: MergedStatus},
-			},
-		},
-		Workflow{label: "workflow:qa",
-			initialState: ProposedStatus,
-			transitions: []Transition{
-				Transition{start: ProposedStatus, end: InProgressStatus},
-				Transition{start: InProgressStatus, end: DoneStatus},
-			},
-		},
+		}
+		transitions[i] = Transition{start: start, end: end, hook: tc.Hook, hookTimeout: hookTimeout, guards: tc.Guards, allowed: tc.Allowed, trigger: tc.Trigger}
 	}
+
+	w.initialState = initialState
+	w.transitions = transitions
+
+	return nil
 }
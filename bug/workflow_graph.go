@@ -0,0 +1,96 @@
+package bug
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dot renders the workflow state machine as a Graphviz DOT graph. If current
+// isn't empty that state and the transitions leaving it are highlighted.
+func (w *Workflow) Dot(current Status) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "digraph %q {\n", w.label)
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=box, style=rounded];\n")
+	b.WriteString("    \"__start\" [shape=point, label=\"\"];\n")
+
+	for _, s := range w.States() {
+		if s == current {
+			fmt.Fprintf(&b, "    %q [style=\"rounded,filled,bold\", fillcolor=orange];\n", s)
+		} else {
+			fmt.Fprintf(&b, "    %q;\n", s)
+		}
+	}
+
+	fmt.Fprintf(&b, "    \"__start\" -> %q;\n", w.initialState)
+
+	for _, t := range w.transitions {
+		var attrs []string
+		if label := t.graphLabel(); label != "" {
+			attrs = append(attrs, fmt.Sprintf("label=%q", label))
+		}
+		if t.start == current {
+			attrs = append(attrs, "penwidth=2", "color=orange")
+		}
+
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, "    %q -> %q [%s];\n", t.start, t.end, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&b, "    %q -> %q;\n", t.start, t.end)
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid renders the workflow state machine as a Mermaid state diagram. If
+// current isn't empty that state is highlighted.
+func (w *Workflow) Mermaid(current Status) string {
+	var b strings.Builder
+
+	// State names can contain characters Mermaid doesn't accept in an id
+	states := w.States()
+	ids := make(map[Status]string, len(states))
+
+	b.WriteString("stateDiagram-v2\n")
+	for i, s := range states {
+		ids[s] = fmt.Sprintf("s%d", i)
+		fmt.Fprintf(&b, "    state \"%s\" as %s\n", s, ids[s])
+	}
+
+	fmt.Fprintf(&b, "    [*] --> %s\n", ids[w.initialState])
+
+	for _, t := range w.transitions {
+		if label := t.graphLabel(); label != "" {
+			fmt.Fprintf(&b, "    %s --> %s : %s\n", ids[t.start], ids[t.end], label)
+		} else {
+			fmt.Fprintf(&b, "    %s --> %s\n", ids[t.start], ids[t.end])
+		}
+	}
+
+	if id, ok := ids[current]; ok {
+		b.WriteString("    classDef current fill:orange,font-weight:bold\n")
+		fmt.Fprintf(&b, "    class %s current\n", id)
+	}
+
+	return b.String()
+}
+
+// graphLabel returns the annotation of the transition in a graph: its trigger,
+// its guards and whether it has a hook
+func (t *Transition) graphLabel() string {
+	var parts []string
+	if t.trigger != "" {
+		parts = append(parts, "on "+string(t.trigger))
+	}
+	for _, g := range t.guards {
+		parts = append(parts, g.String())
+	}
+	if t.hook != "" {
+		parts = append(parts, "hook")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,53 @@
+package bug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var graphWorkflow = Workflow{label: "workflow:test",
+	initialState: ProposedStatus,
+	transitions: []Transition{
+		{start: ProposedStatus, end: InProgressStatus},
+		{start: InProgressStatus, end: "in-test", hook: "true",
+			guards: []Guard{{Type: LabelPresentGuard, Label: "safety"}}},
+		{start: "in-test", end: DoneStatus},
+	},
+}
+
+func TestWorkflow_Dot(t *testing.T) {
+	expected := `digraph "workflow:test" {
+    rankdir=LR;
+    node [shape=box, style=rounded];
+    "__start" [shape=point, label=""];
+    "proposed";
+    "inprogress" [style="rounded,filled,bold", fillcolor=orange];
+    "in-test";
+    "done";
+    "__start" -> "proposed";
+    "proposed" -> "inprogress";
+    "inprogress" -> "in-test" [label="label-present(safety), hook", penwidth=2, color=orange];
+    "in-test" -> "done";
+}
+`
+	assert.Equal(t, expected, graphWorkflow.Dot(InProgressStatus))
+	assert.NotContains(t, graphWorkflow.Dot(""), "orange")
+}
+
+func TestWorkflow_Mermaid(t *testing.T) {
+	expected := `stateDiagram-v2
+    state "proposed" as s0
+    state "inprogress" as s1
+    state "in-test" as s2
+    state "done" as s3
+    [*] --> s0
+    s0 --> s1
+    s1 --> s2 : label-present(safety), hook
+    s2 --> s3
+    classDef current fill:orange,font-weight:bold
+    class s2 current
+`
+	assert.Equal(t, expected, graphWorkflow.Mermaid("in-test"))
+	assert.NotContains(t, graphWorkflow.Mermaid(""), "classDef")
+}
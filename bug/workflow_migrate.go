@@ -0,0 +1,98 @@
+package bug
+
+import (
+	"fmt"
+
+	"github.com/daedaleanai/git-ticket/identity"
+)
+
+// MigrationMetadataKey is the metadata set on the operations of a workflow
+// migration, its value is the label of the workflow migrated to. The status
+// changes of a migration aren't transitions of the workflow.
+const MigrationMetadataKey = "workflow-migration"
+
+// WorkflowMigration describes how a ticket is moved to a workflow
+type WorkflowMigration struct {
+	FromWorkflow Label
+	ToWorkflow   Label
+	FromStatus   Status
+	ToStatus     Status
+}
+
+// IsNoop returns true if the migration doesn't change the ticket
+func (m WorkflowMigration) IsNoop() bool {
+	return m.FromWorkflow == m.ToWorkflow && m.FromStatus == m.ToStatus
+}
+
+// PlanWorkflowMigration works out where the snapshot ends up when moved to the
+// given workflow. The current status is kept unless the mapping gives another
+// one, the resulting status must be a state of the workflow the tickets can get
+// in through its transitions.
+func PlanWorkflowMigration(snap *Snapshot, to *Workflow, mapping map[Status]Status) (WorkflowMigration, error) {
+	m := WorkflowMigration{
+		ToWorkflow: to.label,
+		FromStatus: snap.Status,
+		ToStatus:   snap.Status,
+	}
+
+	for _, l := range snap.Labels {
+		if l.IsWorkflow() {
+			m.FromWorkflow = l
+			break
+		}
+	}
+
+	if s, ok := mapping[snap.Status]; ok {
+		m.ToStatus = s
+	}
+
+	if !statusExist(to.States(), m.ToStatus) {
+		return m, fmt.Errorf("status %s is not a state of %s", m.ToStatus, to.label)
+	}
+
+	if !statusExist(to.ReachableStates(), m.ToStatus) {
+		return m, fmt.Errorf("status %s of %s can't be reached from its initial state %s",
+			m.ToStatus, to.label, to.initialState)
+	}
+
+	return m, nil
+}
+
+// MigrateWorkflow is a convenience function to move a ticket to a workflow. The
+// workflow label is swapped and the status set as planned by
+// PlanWorkflowMigration, without running the transition guards or hooks.
+func MigrateWorkflow(b Interface, author identity.Interface, unixTime int64, to *Workflow, mapping map[Status]Status) (WorkflowMigration, []Operation, error) {
+	snap := b.Compile()
+
+	m, err := PlanWorkflowMigration(&snap, to, mapping)
+	if err != nil {
+		return m, nil, err
+	}
+
+	var ops []Operation
+
+	if m.FromWorkflow != m.ToWorkflow {
+		var removed []Label
+		if m.FromWorkflow != "" {
+			removed = []Label{m.FromWorkflow}
+		}
+		ops = append(ops, NewLabelChangeOperation(author, unixTime, []Label{m.ToWorkflow}, removed))
+	}
+
+	if m.FromStatus != m.ToStatus {
+		ops = append(ops, NewSetStatusOp(author, unixTime, m.ToStatus))
+	}
+
+	for _, op := range ops {
+		op.SetMetadata(MigrationMetadataKey, string(m.ToWorkflow))
+		if err := op.Validate(); err != nil {
+			return m, nil, err
+		}
+	}
+
+	for _, op := range ops {
+		b.Append(op)
+	}
+
+	return m, ops, nil
+}
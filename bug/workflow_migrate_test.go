@@ -0,0 +1,88 @@
+package bug
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daedaleanai/git-ticket/identity"
+)
+
+func TestWorkflow_Migrate(t *testing.T) {
+	var rene = identity.NewBare("René Descartes", "rene@descartes.fr")
+	unix := time.Now().Unix()
+
+	var configs *ConfigCache
+	eng := configs.FindWorkflow("workflow:eng")
+	require.NotNil(t, eng)
+
+	b := NewBug()
+	b.Append(NewCreateOp(rene, unix, "title", "message", nil))
+	b.Append(NewLabelChangeOperation(rene, unix, []Label{"workflow:qa"}, nil))
+	b.Append(NewSetStatusOp(rene, unix, DoneStatus))
+
+	// done isn't a state of workflow:eng
+	snap := b.Compile()
+	_, err := PlanWorkflowMigration(&snap, eng, nil)
+	assert.Error(t, err)
+
+	_, ops, err := MigrateWorkflow(b, rene, unix, eng, map[Status]Status{"bogus": VettedStatus})
+	assert.Error(t, err)
+	assert.Nil(t, ops)
+
+	m, ops, err := MigrateWorkflow(b, rene, unix, eng, map[Status]Status{DoneStatus: MergedStatus})
+	require.NoError(t, err)
+	assert.Len(t, ops, 2)
+	assert.Equal(t, WorkflowMigration{
+		FromWorkflow: "workflow:qa",
+		ToWorkflow:   "workflow:eng",
+		FromStatus:   DoneStatus,
+		ToStatus:     MergedStatus,
+	}, m)
+
+	snap = b.Compile()
+	assert.Equal(t, []Label{"workflow:eng"}, snap.Labels)
+	assert.Equal(t, MergedStatus, snap.Status)
+
+	// Migrating again changes nothing
+	m, ops, err = MigrateWorkflow(b, rene, unix, eng, nil)
+	require.NoError(t, err)
+	assert.True(t, m.IsNoop())
+	assert.Empty(t, ops)
+}
+
+func TestWorkflow_MigrateUnreachable(t *testing.T) {
+	var rene = identity.NewBare("René Descartes", "rene@descartes.fr")
+	unix := time.Now().Unix()
+
+	// Nothing leads to vetted
+	wf := &Workflow{label: "workflow:test",
+		initialState: ProposedStatus,
+		transitions: []Transition{
+			{start: ProposedStatus, end: InProgressStatus},
+			{start: VettedStatus, end: DoneStatus},
+		},
+	}
+	require.NoError(t, wf.Validate())
+	assert.Equal(t, []Status{ProposedStatus, InProgressStatus}, wf.ReachableStates())
+
+	b := NewBug()
+	b.Append(NewCreateOp(rene, unix, "title", "message", nil))
+	b.Append(NewLabelChangeOperation(rene, unix, []Label{"workflow:eng"}, nil))
+	b.Append(NewSetStatusOp(rene, unix, VettedStatus))
+
+	_, ops, err := MigrateWorkflow(b, rene, unix, wf, nil)
+	assert.Error(t, err)
+	assert.Nil(t, ops)
+
+	_, ops, err = MigrateWorkflow(b, rene, unix, wf, map[Status]Status{VettedStatus: DoneStatus})
+	assert.Error(t, err)
+	assert.Nil(t, ops)
+
+	m, ops, err := MigrateWorkflow(b, rene, unix, wf, map[Status]Status{VettedStatus: InProgressStatus})
+	require.NoError(t, err)
+	assert.Len(t, ops, 2)
+	assert.Equal(t, InProgressStatus, m.ToStatus)
+}
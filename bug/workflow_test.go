@@ -2,6 +2,7 @@ package bug
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,34 +24,35 @@ var testWorkflow = Workflow{label: "workflow:test",
 }
 
 func TestWorkflow_FindWorkflow(t *testing.T) {
-	if wf := FindWorkflow("workflow:eng"); wf == nil || wf.label != "workflow:eng" {
+	var configs *ConfigCache
+
+	if wf := configs.FindWorkflow("workflow:eng"); wf == nil || wf.label != "workflow:eng" {
 		t.Fatal("Finding workflow:eng failed")
 	}
 
-	if wf := FindWorkflow("workflow:qa"); wf == nil || wf.label != "workflow:qa" {
+	if wf := configs.FindWorkflow("workflow:qa"); wf == nil || wf.label != "workflow:qa" {
 		t.Fatal("Finding workflow:qa failed")
 	}
 
-	if FindWorkflow("workflow:XYZGASH") != nil {
+	if configs.FindWorkflow("workflow:XYZGASH") != nil {
 		t.Fatal("FindWorkflow returned reference to non-existant workflow")
 	}
 }
 
 func TestWorkflow_NextStates(t *testing.T) {
 	// The valid next states for each status in the testWorkflow
-	var nextStates = [][]Status{
-		nil,                                // first status is 1
-		{VettedStatus},                     // from ProposedStatus
-		{ProposedStatus, InProgressStatus}, // from VettedStatus
-		{InReviewStatus},                   // from InProgressStatus
-		{InProgressStatus, ReviewedStatus}, // from InReviewStatus
-		{AcceptedStatus},                   // from ReviewedStatus
-		{MergedStatus},                     // from AcceptedStatus
-		{AcceptedStatus, DoneStatus},       // from MergedStatus
-		nil,                                // from DoneStatus
+	var nextStates = map[Status][]Status{
+		ProposedStatus:   {VettedStatus},
+		VettedStatus:     {ProposedStatus, InProgressStatus},
+		InProgressStatus: {InReviewStatus},
+		InReviewStatus:   {InProgressStatus, ReviewedStatus},
+		ReviewedStatus:   {AcceptedStatus},
+		AcceptedStatus:   {MergedStatus},
+		MergedStatus:     {AcceptedStatus, DoneStatus},
+		DoneStatus:       nil,
 	}
 
-	for currentState := FirstStatus; currentState <= LastStatus; currentState++ {
+	for _, currentState := range builtinStatuses {
 		next, err := testWorkflow.NextStates(currentState)
 		if err != nil {
 			t.Fatal("Invalid next states", currentState, ">", next, "(error", err, ")")
@@ -61,20 +63,19 @@ func TestWorkflow_NextStates(t *testing.T) {
 
 func TestWorkflow_ValidateTransition(t *testing.T) {
 	// The valid transitions for each status in the testWorkflow
-	var validTransitions = [][]Status{
-		nil,                                // first status is 1
-		{VettedStatus},                     // from ProposedStatus
-		{ProposedStatus, InProgressStatus}, // from VettedStatus
-		{InReviewStatus},                   // from InProgressStatus
-		{InProgressStatus, ReviewedStatus}, // from InReviewStatus
-		{AcceptedStatus},                   // from ReviewedStatus
-		{MergedStatus},                     // from AcceptedStatus
-		{DoneStatus},                       // from MergedStatus
-		nil,                                // from DoneStatus
+	var validTransitions = map[Status][]Status{
+		ProposedStatus:   {VettedStatus},
+		VettedStatus:     {ProposedStatus, InProgressStatus},
+		InProgressStatus: {InReviewStatus},
+		InReviewStatus:   {InProgressStatus, ReviewedStatus},
+		ReviewedStatus:   {AcceptedStatus},
+		AcceptedStatus:   {MergedStatus},
+		MergedStatus:     {DoneStatus},
+		DoneStatus:       nil,
 	}
 
 	// Test validation of state transition
-	for from := FirstStatus; from <= LastStatus; from++ {
+	for _, from := range builtinStatuses {
 		for _, to := range validTransitions[from] {
 			if err := testWorkflow.ValidateTransition(from, to); err != nil {
 				t.Fatal("State transition " + from.String() + " > " + to.String() + " flagged invalid when it isn't")
@@ -86,3 +87,71 @@ func TestWorkflow_ValidateTransition(t *testing.T) {
 		t.Fatal("State transition proposed > merged flagged valid when it isn't")
 	}
 }
+
+func TestWorkflow_ParseWorkflows(t *testing.T) {
+	data := `{
+		"workflow:test": {
+			"initialState": "proposed",
+			"transitions": [
+				{"start": "proposed", "end": "vetted", "hook": "true", "hookTimeout": "5s"},
+				{"start": "vetted", "end": "done"}
+			]
+		}
+	}`
+
+	workflows, err := ParseWorkflows([]byte(data))
+	assert.NoError(t, err)
+	assert.Len(t, workflows, 1)
+	assert.Equal(t, Label("workflow:test"), workflows[0].label)
+	assert.Equal(t, ProposedStatus, workflows[0].initialState)
+	assert.Equal(t, []Transition{
+		{start: ProposedStatus, end: VettedStatus, hook: "true", hookTimeout: 5 * time.Second},
+		{start: VettedStatus, end: DoneStatus},
+	}, workflows[0].transitions)
+
+	var invalidConfigs = []string{
+		// not a map of workflows
+		`[]`,
+		// label without the workflow prefix
+		`{"test": {"initialState": "proposed", "transitions": []}}`,
+		// invalid state names
+		`{"workflow:test": {"initialState": "", "transitions": []}}`,
+		`{"workflow:test": {"initialState": "proposed", "transitions": [{"start": "proposed", "end": "x y"}]}}`,
+		// duplicate transition
+		`{"workflow:test": {"initialState": "proposed", "transitions": [
+			{"start": "proposed", "end": "vetted"},
+			{"start": "proposed", "end": "vetted"}]}}`,
+		// malformed hook and hook timeout
+		`{"workflow:test": {"initialState": "proposed", "transitions": [{"start": "proposed", "end": "vetted", "hook": "echo 'x"}]}}`,
+		`{"workflow:test": {"initialState": "proposed", "transitions": [{"start": "proposed", "end": "vetted", "hook": "true", "hookTimeout": "soon"}]}}`,
+	}
+
+	for _, c := range invalidConfigs {
+		_, err := ParseWorkflows([]byte(c))
+		assert.Error(t, err, c)
+	}
+}
+
+func TestWorkflow_CustomStates(t *testing.T) {
+	data := `{
+		"workflow:hw": {
+			"initialState": "proposed",
+			"transitions": [
+				{"start": "proposed", "end": "blocked"},
+				{"start": "blocked", "end": "Verification"},
+				{"start": "verification", "end": "done"}
+			]
+		}
+	}`
+
+	workflows, err := ParseWorkflows([]byte(data))
+	assert.NoError(t, err)
+	assert.Equal(t, []Status{ProposedStatus, "blocked", "verification", DoneStatus}, workflows[0].States())
+
+	next, err := workflows[0].NextStates("blocked")
+	assert.NoError(t, err)
+	assert.Equal(t, []Status{"verification"}, next)
+
+	assert.Equal(t, "set IN PROGRESS", InProgressStatus.Action())
+	assert.Equal(t, "set BLOCKED", Status("blocked").Action())
+}
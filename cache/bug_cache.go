@@ -2,11 +2,13 @@ package cache
 
 import (
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/daedaleanai/git-ticket/bug"
 	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/identity"
 	"github.com/daedaleanai/git-ticket/repository"
 )
 
@@ -36,6 +38,14 @@ func (c *BugCache) Snapshot() *bug.Snapshot {
 	return c.bug.Snapshot()
 }
 
+// OperationCommits returns the hash of the git commit storing each committed
+// operation, by operation id
+func (c *BugCache) OperationCommits() map[entity.Id]repository.Hash {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.bug.OperationCommits()
+}
+
 func (c *BugCache) Id() entity.Id {
 	return c.bug.Id()
 }
@@ -112,7 +122,7 @@ func (c *BugCache) ChangeLabels(added []string, removed []string) ([]bug.LabelCh
 
 func (c *BugCache) ChangeLabelsRaw(author *IdentityCache, unixTime int64, added []string, removed []string, metadata map[string]string) ([]bug.LabelChangeResult, *bug.LabelChangeOperation, error) {
 	c.mu.Lock()
-	changes, op, err := bug.ChangeLabels(c.bug, author.Identity, unixTime, added, removed)
+	changes, op, err := bug.ChangeLabels(c.bug, author.Identity, unixTime, added, removed, c.repoCache.configs)
 	if err != nil {
 		c.mu.Unlock()
 		return changes, nil, err
@@ -253,7 +263,22 @@ func (c *BugCache) SetChecklistRaw(author *IdentityCache, unixTime int64, cl bug
 		op.SetMetadata(key, value)
 	}
 
-	return op, c.notifyUpdated()
+	if err := c.notifyUpdated(); err != nil {
+		return nil, err
+	}
+
+	states := c.Snapshot().GetChecklistCompoundStates()
+	allPassed := len(states) > 0
+	for _, s := range states {
+		if s != bug.Passed {
+			allPassed = false
+		}
+	}
+	if allPassed {
+		c.fireEventAsWarning(author, unixTime, bug.ChecklistsPassedEvent)
+	}
+
+	return op, nil
 }
 
 func (c *BugCache) RmReview(id string) (*bug.SetReviewOperation, error) {
@@ -273,9 +298,13 @@ func (c *BugCache) SetReview(review *bug.ReviewInfo) (*bug.SetReviewOperation, e
 		return nil, err
 	}
 
-	// Before committing resolve all the Phabricator IDs to identities
+	// Before committing resolve all the review users to identities. The users
+	// without identity are kept as is, until matched with "user phab-sync".
 	for i, t := range review.Updates {
-		user, err := c.repoCache.ResolveIdentityPhabID(t.PhabUser)
+		user, err := c.repoCache.ResolveIdentityReviewer(t.PhabUser)
+		if err == identity.ErrIdentityNotExist {
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -295,7 +324,15 @@ func (c *BugCache) SetReviewRaw(author *IdentityCache, unixTime int64, metadata
 		op.SetMetadata(key, value)
 	}
 
-	return op, c.notifyUpdated()
+	if err := c.notifyUpdated(); err != nil {
+		return nil, err
+	}
+
+	if stored, ok := c.Snapshot().Reviews[review.RevisionId]; ok && stored.IsAccepted() {
+		c.fireEventAsWarning(author, unixTime, bug.ReviewAcceptedEvent)
+	}
+
+	return op, nil
 }
 
 func (c *BugCache) SetStatus(status bug.Status) (*bug.SetStatusOperation, error) {
@@ -308,7 +345,7 @@ func (c *BugCache) SetStatus(status bug.Status) (*bug.SetStatusOperation, error)
 }
 
 func (c *BugCache) SetStatusRaw(author *IdentityCache, unixTime int64, metadata map[string]string, status bug.Status) (*bug.SetStatusOperation, error) {
-	op, err := bug.SetStatus(c.bug, author.Identity, unixTime, status)
+	op, err := bug.SetStatus(c.bug, author.Identity, unixTime, status, c.repoCache.configs, c.repoCache.GetPath())
 	if err != nil {
 		return nil, err
 	}
@@ -320,6 +357,79 @@ func (c *BugCache) SetStatusRaw(author *IdentityCache, unixTime int64, metadata
 	return op, c.notifyUpdated()
 }
 
+func (c *BugCache) MigrateWorkflow(to *bug.Workflow, mapping map[bug.Status]bug.Status) (bug.WorkflowMigration, error) {
+	author, err := c.repoCache.GetUserIdentity()
+	if err != nil {
+		return bug.WorkflowMigration{}, err
+	}
+
+	return c.MigrateWorkflowRaw(author, time.Now().Unix(), nil, to, mapping)
+}
+
+func (c *BugCache) MigrateWorkflowRaw(author *IdentityCache, unixTime int64, metadata map[string]string, to *bug.Workflow, mapping map[bug.Status]bug.Status) (bug.WorkflowMigration, error) {
+	c.mu.Lock()
+	migration, ops, err := bug.MigrateWorkflow(c.bug, author.Identity, unixTime, to, mapping)
+	if err != nil {
+		c.mu.Unlock()
+		return migration, err
+	}
+
+	for _, op := range ops {
+		for key, value := range metadata {
+			op.SetMetadata(key, value)
+		}
+	}
+
+	c.mu.Unlock()
+
+	return migration, c.notifyUpdated()
+}
+
+// FireEvent makes the automatic transitions of the ticket workflow triggered by
+// the event
+func (c *BugCache) FireEvent(event bug.EventType) ([]*bug.SetStatusOperation, error) {
+	author, err := c.repoCache.GetUserIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.FireEventRaw(author, time.Now().Unix(), event)
+}
+
+func (c *BugCache) FireEventRaw(author *IdentityCache, unixTime int64, event bug.EventType) ([]*bug.SetStatusOperation, error) {
+	var ops []*bug.SetStatusOperation
+
+	// The workflows can't have loops of automatic transitions, a state is
+	// still never left twice for the same event
+	left := make(map[bug.Status]bool)
+
+	for {
+		snap := c.Snapshot()
+		next, ok := snap.AutomaticTransition(c.repoCache.configs, event)
+		if !ok || left[snap.Status] {
+			break
+		}
+		left[snap.Status] = true
+
+		metadata := map[string]string{bug.AutomationMetadataKey: string(event)}
+		op, err := c.SetStatusRaw(author, unixTime, metadata, next)
+		if err != nil {
+			return ops, err
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+// fireEventAsWarning fires the event, an automatic transition that can't be
+// made doesn't fail the operation that raised the event
+func (c *BugCache) fireEventAsWarning(author *IdentityCache, unixTime int64, event bug.EventType) {
+	if _, err := c.FireEventRaw(author, unixTime, event); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: automatic transition on %s not made: %s\n", event, err)
+	}
+}
+
 func (c *BugCache) SetTitle(title string) (*bug.SetTitleOperation, error) {
 	author, err := c.repoCache.GetUserIdentity()
 	if err != nil {
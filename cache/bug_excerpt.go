@@ -3,6 +3,7 @@ package cache
 import (
 	"encoding/gob"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/daedaleanai/git-ticket/bug"
@@ -41,6 +42,31 @@ type BugExcerpt struct {
 	AuthorId     entity.Id
 
 	CreateMetadata map[string]string
+	// the distinct values of the metadata of all the operations, in the order
+	// of the operations
+	Metadata map[string][]string
+
+	// the compound state of each checklist of the bug
+	Checklists map[bug.Label]bug.ChecklistState
+	Reviews    []ReviewExcerpt
+}
+
+// ReviewExcerpt is a summary of a review of a bug
+type ReviewExcerpt struct {
+	RevisionId string
+	// the latest overall status of the review, e.g. accepted
+	Status    string
+	Reviewers []ReviewerExcerpt
+}
+
+// ReviewerExcerpt is a user who accepted a review, requested changes or
+// commented on it
+type ReviewerExcerpt struct {
+	// the user in the review system, a Phabricator PHID or an email
+	User string
+	// the identity of the user, if known when the review was stored. The filters
+	// prefer the identity matching the user at query time.
+	Id entity.Id
 }
 
 // identity.Bare data are directly embedded in the bug excerpt
@@ -96,6 +122,9 @@ func NewBugExcerpt(b bug.Interface, snap *bug.Snapshot) *BugExcerpt {
 		Title:             snap.Title,
 		LenComments:       len(snap.Comments),
 		CreateMetadata:    b.FirstOp().AllMetadata(),
+		Metadata:          operationsMetadata(snap.Operations),
+		Checklists:        snap.GetChecklistCompoundStates(),
+		Reviews:           reviewExcerpts(snap.Reviews),
 	}
 
 	switch snap.Author.(type) {
@@ -113,6 +142,57 @@ func NewBugExcerpt(b bug.Interface, snap *bug.Snapshot) *BugExcerpt {
 	return e
 }
 
+// operationsMetadata gathers the values of the metadata of the operations
+func operationsMetadata(ops []bug.Operation) map[string][]string {
+	result := make(map[string][]string)
+
+	for _, op := range ops {
+		metadata := op.AllMetadata()
+
+		keys := make([]string, 0, len(metadata))
+		for key := range metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+	nextKey:
+		for _, key := range keys {
+			for _, val := range result[key] {
+				if val == metadata[key] {
+					continue nextKey
+				}
+			}
+			result[key] = append(result[key], metadata[key])
+		}
+	}
+
+	return result
+}
+
+// reviewExcerpts summarizes the reviews, sorted by revision id
+func reviewExcerpts(reviews map[string]bug.ReviewInfo) []ReviewExcerpt {
+	result := make([]ReviewExcerpt, 0, len(reviews))
+
+	for _, review := range reviews {
+		e := ReviewExcerpt{
+			RevisionId: review.RevisionId,
+			Status:     review.LatestOverallStatus(),
+		}
+		for _, u := range review.Reviewers() {
+			r := ReviewerExcerpt{User: u.PhabUser}
+			if u.Author != nil {
+				r.Id = u.Author.Id()
+			}
+			e.Reviewers = append(e.Reviewers, r)
+		}
+		result = append(result, e)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].RevisionId < result[j].RevisionId })
+
+	return result
+}
+
 func (b *BugExcerpt) CreateTime() time.Time {
 	return time.Unix(b.CreateUnixTime, 0)
 }
@@ -194,3 +274,41 @@ func (b BugsByEditTime) Less(i, j int) bool {
 func (b BugsByEditTime) Swap(i, j int) {
 	b[i], b[j] = b[j], b[i]
 }
+
+// BugsByMetadata sorts the bugs by the first value of a metadata, the bugs
+// without it first
+type BugsByMetadata struct {
+	Bugs []*BugExcerpt
+	Key  string
+}
+
+func (b BugsByMetadata) Len() int {
+	return len(b.Bugs)
+}
+
+func (b BugsByMetadata) Less(i, j int) bool {
+	vi, oki := b.value(i)
+	vj, okj := b.value(j)
+
+	if oki != okj {
+		return !oki
+	}
+	if vi != vj {
+		return vi < vj
+	}
+
+	// Keep a stable order for the bugs with the same value
+	return b.Bugs[i].Id < b.Bugs[j].Id
+}
+
+func (b BugsByMetadata) Swap(i, j int) {
+	b.Bugs[i], b.Bugs[j] = b.Bugs[j], b.Bugs[i]
+}
+
+func (b BugsByMetadata) value(i int) (string, bool) {
+	values := b.Bugs[i].Metadata[b.Key]
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
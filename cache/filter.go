@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/daedaleanai/git-ticket/bug"
@@ -12,6 +13,8 @@ import (
 // This exist mainly to go through the functions of the cache with proper locking.
 type resolver interface {
 	ResolveIdentityExcerpt(id entity.Id) (*IdentityExcerpt, error)
+	ResolveIdentityExcerptReviewer(user string) (*IdentityExcerpt, error)
+	bugContainsWord(id entity.Id, word string) bool
 }
 
 // Filter is a predicate that match a subset of bugs
@@ -124,6 +127,118 @@ func TitleFilter(query string) Filter {
 	}
 }
 
+// CreationFilter return a Filter that match if the bug was created within the
+// time range
+func CreationFilter(r query.TimeRange) Filter {
+	return func(excerpt *BugExcerpt, resolver resolver) bool {
+		return r.Match(excerpt.CreateTime())
+	}
+}
+
+// EditFilter return a Filter that match if the bug was last edited within the
+// time range
+func EditFilter(r query.TimeRange) Filter {
+	return func(excerpt *BugExcerpt, resolver resolver) bool {
+		return r.Match(excerpt.EditTime())
+	}
+}
+
+// SearchFilter return a Filter that match if the bug contains all the words of
+// the given text, in its title, its comments or the comments of its reviews
+func SearchFilter(text string) Filter {
+	words := searchWords(text)
+	return func(excerpt *BugExcerpt, resolver resolver) bool {
+		for _, word := range words {
+			if !resolver.bugContainsWord(excerpt.Id, word) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MetadataFilter return a Filter that match if any operation of the bug has the
+// metadata, with the given value unless only its existence is checked
+func MetadataFilter(filter query.MetadataFilter) Filter {
+	return func(excerpt *BugExcerpt, resolver resolver) bool {
+		values, ok := excerpt.Metadata[filter.Key]
+		if !ok || !filter.HasValue {
+			return ok
+		}
+		for _, value := range values {
+			if value == filter.Value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ChecklistFilter return a Filter that match if a checklist of the bug, or the
+// one of the given label, is in the given compound state
+func ChecklistFilter(filter query.ChecklistFilter) Filter {
+	return func(excerpt *BugExcerpt, resolver resolver) bool {
+		if filter.Label != "" {
+			state, ok := excerpt.Checklists[filter.Label]
+			return ok && state == filter.State
+		}
+		for _, state := range excerpt.Checklists {
+			if state == filter.State {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ReviewFilter return a Filter that match if a review of the bug has the given
+// overall status, or if the bug has no review for the status "none"
+func ReviewFilter(status string) Filter {
+	return func(excerpt *BugExcerpt, resolver resolver) bool {
+		if status == "none" {
+			return len(excerpt.Reviews) == 0
+		}
+		for _, review := range excerpt.Reviews {
+			if review.Status == status {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ReviewerFilter return a Filter that match a user who accepted a review of
+// the bug, requested changes or commented on it. The identity currently
+// matching the review system user is preferred to the one known when the
+// review was stored, so that the users matched since are found.
+func ReviewerFilter(query string) Filter {
+	return func(excerpt *BugExcerpt, resolver resolver) bool {
+		query = strings.ToLower(query)
+
+		for _, review := range excerpt.Reviews {
+			for _, reviewer := range review.Reviewers {
+				identityExcerpt, err := resolver.ResolveIdentityExcerptReviewer(reviewer.User)
+				if err != nil && reviewer.Id != "" {
+					identityExcerpt, err = resolver.ResolveIdentityExcerpt(reviewer.Id)
+				}
+
+				// The users without identity are matched as is
+				if err != nil {
+					if strings.Contains(strings.ToLower(reviewer.User), query) {
+						return true
+					}
+					continue
+				}
+
+				if identityExcerpt.Match(query) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
 // NoLabelFilter return a Filter that match the absence of labels
 func NoLabelFilter() Filter {
 	return func(excerpt *BugExcerpt, resolver resolver) bool {
@@ -140,6 +255,13 @@ type Matcher struct {
 	Participant []Filter
 	Label       []Filter
 	Title       []Filter
+	Created     []Filter
+	Edited      []Filter
+	Search      []Filter
+	Metadata    []Filter
+	Checklist   []Filter
+	Review      []Filter
+	Reviewer    []Filter
 	NoFilters   []Filter
 }
 
@@ -169,10 +291,88 @@ func compileMatcher(filters query.Filters) *Matcher {
 	for _, value := range filters.Title {
 		result.Title = append(result.Title, TitleFilter(value))
 	}
+	for _, value := range filters.Created {
+		result.Created = append(result.Created, CreationFilter(value))
+	}
+	for _, value := range filters.Edited {
+		result.Edited = append(result.Edited, EditFilter(value))
+	}
+	for _, value := range filters.Search {
+		result.Search = append(result.Search, SearchFilter(value))
+	}
+	for _, value := range filters.Metadata {
+		result.Metadata = append(result.Metadata, MetadataFilter(value))
+	}
+	for _, value := range filters.Checklist {
+		result.Checklist = append(result.Checklist, ChecklistFilter(value))
+	}
+	for _, value := range filters.Review {
+		result.Review = append(result.Review, ReviewFilter(value))
+	}
+	for _, value := range filters.Reviewer {
+		result.Reviewer = append(result.Reviewer, ReviewerFilter(value))
+	}
+	if filters.NoLabel {
+		result.NoFilters = append(result.NoFilters, NoLabelFilter())
+	}
 
 	return result
 }
 
+// compileExpression transform a query expression into a Filter for the cache
+func compileExpression(expr query.Expression) Filter {
+	switch expr := expr.(type) {
+	case *query.And:
+		matcher := compileMatcher(expr.Filters)
+		operands := compileExpressions(expr.Operands)
+		return func(excerpt *BugExcerpt, resolver resolver) bool {
+			if !matcher.Match(excerpt, resolver) {
+				return false
+			}
+			for _, f := range operands {
+				if !f(excerpt, resolver) {
+					return false
+				}
+			}
+			return true
+		}
+
+	case *query.Or:
+		operands := compileExpressions(expr.Operands)
+		return func(excerpt *BugExcerpt, resolver resolver) bool {
+			for _, f := range operands {
+				if f(excerpt, resolver) {
+					return true
+				}
+			}
+			return false
+		}
+
+	case *query.Not:
+		operand := compileExpression(expr.Operand)
+		return func(excerpt *BugExcerpt, resolver resolver) bool {
+			return !operand(excerpt, resolver)
+		}
+
+	default:
+		panic(fmt.Sprintf("unknown query expression %T", expr))
+	}
+}
+
+func compileExpressions(exprs []query.Expression) []Filter {
+	result := make([]Filter, len(exprs))
+	for i, expr := range exprs {
+		result[i] = compileExpression(expr)
+	}
+	return result
+}
+
+// compileQuery transform the filters and expressions of a query into a Filter
+// for the cache
+func compileQuery(q *query.Query) Filter {
+	return compileExpression(&query.And{Filters: q.Filters, Operands: q.Expressions})
+}
+
 // Match check if a bug match the set of filters
 func (f *Matcher) Match(excerpt *BugExcerpt, resolver resolver) bool {
 	if match := f.orMatch(f.Status, excerpt, resolver); !match {
@@ -207,6 +407,34 @@ func (f *Matcher) Match(excerpt *BugExcerpt, resolver resolver) bool {
 		return false
 	}
 
+	if match := f.andMatch(f.Created, excerpt, resolver); !match {
+		return false
+	}
+
+	if match := f.andMatch(f.Edited, excerpt, resolver); !match {
+		return false
+	}
+
+	if match := f.andMatch(f.Search, excerpt, resolver); !match {
+		return false
+	}
+
+	if match := f.andMatch(f.Metadata, excerpt, resolver); !match {
+		return false
+	}
+
+	if match := f.andMatch(f.Checklist, excerpt, resolver); !match {
+		return false
+	}
+
+	if match := f.orMatch(f.Review, excerpt, resolver); !match {
+		return false
+	}
+
+	if match := f.orMatch(f.Reviewer, excerpt, resolver); !match {
+		return false
+	}
+
 	return true
 }
 
@@ -1,9 +1,17 @@
 package cache
 
 import (
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/identity"
+	"github.com/daedaleanai/git-ticket/query"
 )
 
 func TestTitleFilter(t *testing.T) {
@@ -32,3 +40,114 @@ func TestTitleFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryFilter(t *testing.T) {
+	date := func(year int, month time.Month, day int) int64 {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.Local).Unix()
+	}
+
+	excerpts := map[string]*BugExcerpt{
+		"hw": {Status: bug.ProposedStatus, Labels: []bug.Label{"hw"},
+			CreateUnixTime: date(2026, 1, 10), EditUnixTime: date(2026, 5, 1),
+			Metadata:   map[string][]string{"origin": {"jira"}, "jira-key": {"HW-1", "HW-2"}},
+			Checklists: map[bug.Label]bug.ChecklistState{"checklist:code": bug.Failed, "checklist:hw": bug.TBD},
+			Reviews: []ReviewExcerpt{
+				{RevisionId: "D1", Status: "needs-revision", Reviewers: []ReviewerExcerpt{{User: "PHID-USER-bob"}}},
+				{RevisionId: "D2", Status: "accepted", Reviewers: []ReviewerExcerpt{{User: "carol@example.com"}, {User: "PHID-USER-x1"}}},
+			}},
+		"fpga": {Status: bug.MergedStatus, Labels: []bug.Label{"fpga", "checklist:code"},
+			CreateUnixTime: date(2025, 12, 1), EditUnixTime: date(2026, 2, 1),
+			Metadata:   map[string][]string{"origin": {"github"}},
+			Checklists: map[bug.Label]bug.ChecklistState{"checklist:code": bug.Passed},
+			Reviews: []ReviewExcerpt{
+				{RevisionId: "G1", Status: "published", Reviewers: []ReviewerExcerpt{{User: "bob@example.com"}}},
+			}},
+		"hw-fpga": {Status: bug.InProgressStatus, Labels: []bug.Label{"hw", "fpga"},
+			CreateUnixTime: date(2026, 3, 5), EditUnixTime: date(2026, 3, 6)},
+		"unlabeled": {Status: bug.ProposedStatus,
+			CreateUnixTime: date(2026, 6, 1), EditUnixTime: date(2026, 6, 2)},
+	}
+
+	tests := []struct {
+		query   string
+		matches []string
+	}{
+		{"", []string{"fpga", "hw", "hw-fpga", "unlabeled"}},
+		{"label:hw", []string{"hw", "hw-fpga"}},
+		{"label:checklist:code", []string{"fpga"}},
+		{"status:proposed status:merged", []string{"fpga", "hw", "unlabeled"}},
+		{"label:hw OR label:fpga", []string{"fpga", "hw", "hw-fpga"}},
+		{"(label:hw OR label:fpga) -status:merged", []string{"hw", "hw-fpga"}},
+		{"NOT label:hw", []string{"fpga", "unlabeled"}},
+		{"no:label", []string{"unlabeled"}},
+		{"-no:label -(label:hw label:fpga)", []string{"fpga", "hw"}},
+		{"status:proposed (label:fpga OR no:label)", []string{"unlabeled"}},
+		{"created:2026", []string{"hw", "hw-fpga", "unlabeled"}},
+		{"created:>=2026-03-05", []string{"hw-fpga", "unlabeled"}},
+		{"created:>2026-03-05", []string{"unlabeled"}},
+		{"created:<2026-01-10", []string{"fpga"}},
+		{"created:<=2026-01-10", []string{"fpga", "hw"}},
+		{"edited:2026-02..2026-05", []string{"fpga", "hw", "hw-fpga"}},
+		{"created:2026 edited:<2026-04", []string{"hw-fpga"}},
+		{"label:hw OR edited:2026-06", []string{"hw", "hw-fpga", "unlabeled"}},
+		{"metadata:origin", []string{"fpga", "hw"}},
+		{"metadata:origin=jira", []string{"hw"}},
+		{"metadata:jira-key=HW-2", []string{"hw"}},
+		{"metadata:jira-key=HW", nil},
+		{"-metadata:origin", []string{"hw-fpga", "unlabeled"}},
+		{"checklist:failed", []string{"hw"}},
+		{"checklist:tbd", []string{"hw"}},
+		{"checklist:label:checklist:code=passed", []string{"fpga"}},
+		{"checklist:label:checklist:hw=passed", nil},
+		{"review:accepted", []string{"hw"}},
+		{"review:needs-revision review:published", []string{"fpga", "hw"}},
+		{"review:none", []string{"hw-fpga", "unlabeled"}},
+		{"reviewer:bob", []string{"fpga", "hw"}},
+		{"reviewer:carol -review:needs-revision", nil},
+		{"reviewer:dave", []string{"hw"}},
+	}
+
+	// The review system users are resolved to their current identity
+	resolver := fakeResolver{"PHID-USER-x1": {Id: "dave", Name: "Dave"}}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := query.Parse(tt.query)
+			require.NoError(t, err)
+
+			filter := compileQuery(q)
+
+			var matches []string
+			for name, excerpt := range excerpts {
+				if filter(excerpt, resolver) {
+					matches = append(matches, name)
+				}
+			}
+			sort.Strings(matches)
+			assert.Equal(t, tt.matches, matches)
+		})
+	}
+}
+
+// fakeResolver resolves the identities of the review system users it holds
+type fakeResolver map[string]*IdentityExcerpt
+
+func (r fakeResolver) ResolveIdentityExcerpt(id entity.Id) (*IdentityExcerpt, error) {
+	for _, excerpt := range r {
+		if excerpt.Id == id {
+			return excerpt, nil
+		}
+	}
+	return nil, identity.ErrIdentityNotExist
+}
+
+func (r fakeResolver) ResolveIdentityExcerptReviewer(user string) (*IdentityExcerpt, error) {
+	if excerpt, ok := r[user]; ok {
+		return excerpt, nil
+	}
+	return nil, identity.ErrIdentityNotExist
+}
+
+func (r fakeResolver) bugContainsWord(id entity.Id, word string) bool {
+	return false
+}
@@ -22,6 +22,7 @@ type IdentityExcerpt struct {
 
 	Name              string
 	Login             string
+	Email             string
 	PhabID            string
 	ImmutableMetadata map[string]string
 }
@@ -31,6 +32,7 @@ func NewIdentityExcerpt(i *identity.Identity) *IdentityExcerpt {
 		Id:                i.Id(),
 		Name:              i.Name(),
 		Login:             i.Login(),
+		Email:             i.Email(),
 		PhabID:            i.PhabID(),
 		ImmutableMetadata: i.ImmutableMetadata(),
 	}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/thought-machine/gonduit"
 
 	"github.com/daedaleanai/git-ticket/bug"
 	"github.com/daedaleanai/git-ticket/entity"
@@ -26,7 +27,11 @@ const configRemoteRefPattern = "refs/remotes/%s/configs/"
 
 // 1: original format
 // 2: added cache for identities with a reference in the bug cache
-const formatVersion = 2
+// 3: statuses stored by name
+// 4: emails in the identity cache
+// 5: metadata of all the operations in the bug excerpts
+// 6: checklists and reviews in the bug excerpts
+const formatVersion = 6
 
 // The maximum number of bugs loaded in memory. After that, eviction will be done.
 const defaultMaxLoadedBugs = 1000
@@ -40,9 +45,11 @@ var _ repository.RepoCommon = &RepoCache{}
 // 2. The cache maintain in memory and on disk a pre-digested excerpt for each bug,
 // 		allowing for fast querying the whole set of bugs without having to load
 //		them individually.
-// 3. The cache guarantee that a single instance of a Bug is loaded at once, avoiding
+// 3. The cache maintain in memory and on disk a full-text index of the bugs, allowing
+// 		for searching their text.
+// 4. The cache guarantee that a single instance of a Bug is loaded at once, avoiding
 // 		loss of data that we could have with multiple copies in the same process.
-// 4. The same way, the cache maintain in memory a single copy of the loaded identities.
+// 5. The same way, the cache maintain in memory a single copy of the loaded identities.
 //
 // The cache also protect the on-disk data by locking the git repository for its
 // own usage, by writing a lock file. Of course, normal git operations are not
@@ -64,6 +71,8 @@ type RepoCache struct {
 	bugs map[entity.Id]*BugCache
 	// loadedBugs is an LRU cache that records which bugs the cache has loaded in
 	loadedBugs *LRUIdCache
+	// full-text index of the bugs
+	searchIndex *searchIndex
 
 	muIdentity sync.RWMutex
 	// excerpt of identities data for all identities
@@ -75,10 +84,15 @@ type RepoCache struct {
 	userIdentityId entity.Id
 
 	muConfig sync.RWMutex
+	// the workflows, checklists and groups configured in the repo
+	configs *bug.ConfigCache
 
 	// the cache of commits
 	muCommit sync.RWMutex
 	commits  map[repository.Hash]*object.Commit
+
+	// phabClient opens the connection to Phabricator
+	phabClient func() (*gonduit.Conn, error)
 }
 
 func NewRepoCache(r repository.ClockedRepo) (*RepoCache, error) {
@@ -94,6 +108,8 @@ func NewNamedRepoCache(r repository.ClockedRepo, name string) (*RepoCache, error
 		loadedBugs:    NewLRUIdCache(),
 		identities:    make(map[entity.Id]*IdentityCache),
 		commits:       make(map[repository.Hash]*object.Commit),
+		configs:       bug.NewConfigCache(r),
+		phabClient:    repository.GetConfiguredPhabClient,
 	}
 
 	err := c.lock()
@@ -115,6 +131,27 @@ func NewNamedRepoCache(r repository.ClockedRepo, name string) (*RepoCache, error
 	return c, c.write()
 }
 
+// SetPhabricator makes the cache query the Phabricator server at the given url
+// with the given Conduit API token, instead of the one configured in the
+// working directory
+func (c *RepoCache) SetPhabricator(phabUrl, apiToken string) {
+	c.phabClient = func() (*gonduit.Conn, error) {
+		return repository.GetPhabClient(phabUrl, apiToken)
+	}
+}
+
+// ReviewProviders returns the review providers configured in the repository,
+// connecting to the Phabricator server of the cache
+func (c *RepoCache) ReviewProviders() (*bug.ReviewProviders, error) {
+	return bug.NewReviewProviders(c.repo, c.phabClient)
+}
+
+// PhabricatorProvider returns the provider of the reviews of the Phabricator
+// server of the cache
+func (c *RepoCache) PhabricatorProvider() *bug.PhabricatorProvider {
+	return bug.NewPhabricatorProvider(c.phabClient)
+}
+
 // setCacheSize change the maximum number of loaded bugs
 func (c *RepoCache) setCacheSize(size int) {
 	c.maxLoadedBugs = size
@@ -127,6 +164,10 @@ func (c *RepoCache) load() error {
 	if err != nil {
 		return err
 	}
+	err = c.loadSearchIndex()
+	if err != nil {
+		return err
+	}
 	return c.loadIdentityCache()
 }
 
@@ -136,6 +177,10 @@ func (c *RepoCache) write() error {
 	if err != nil {
 		return err
 	}
+	err = c.writeSearchIndex()
+	if err != nil {
+		return err
+	}
 	return c.writeIdentityCache()
 }
 
@@ -178,6 +223,7 @@ func (c *RepoCache) Close() error {
 	c.identitiesExcerpts = nil
 	c.bugs = make(map[entity.Id]*BugCache)
 	c.bugExcerpts = nil
+	c.searchIndex = nil
 
 	lockPath := repoLockFilePath(c.repo)
 	return os.Remove(lockPath)
@@ -208,6 +254,7 @@ func (c *RepoCache) buildCache() error {
 	_, _ = fmt.Fprintf(os.Stderr, "Building bug cache... ")
 
 	c.bugExcerpts = make(map[entity.Id]*BugExcerpt)
+	c.searchIndex = newSearchIndex()
 
 	allBugs := bug.ReadAllLocalBugs(c.repo)
 
@@ -218,6 +265,7 @@ func (c *RepoCache) buildCache() error {
 
 		snap := b.Bug.Compile()
 		c.bugExcerpts[b.Bug.Id()] = NewBugExcerpt(b.Bug, &snap)
+		c.searchIndex.update(b.Bug.Id(), &snap)
 	}
 
 	_, _ = fmt.Fprintln(os.Stderr, "Done.")
@@ -41,10 +41,15 @@ func (c *RepoCache) bugUpdated(id entity.Id) error {
 	}
 	c.loadedBugs.Get(id)
 	c.bugExcerpts[id] = NewBugExcerpt(b.bug, b.Snapshot())
+	c.searchIndex.update(id, b.Snapshot())
 	c.muBug.Unlock()
 
-	// we only need to write the bug cache
-	return c.writeBugCache()
+	// we only need to write the bug cache and the search index
+	err := c.writeBugCache()
+	if err != nil {
+		return err
+	}
+	return c.writeSearchIndex()
 }
 
 // load will try to read from the disk the bug cache file
@@ -252,12 +257,12 @@ func (c *RepoCache) QueryBugs(q *query.Query) []entity.Id {
 		return c.AllBugsIds()
 	}
 
-	matcher := compileMatcher(q.Filters)
+	matcher := compileQuery(q)
 
 	var filtered []*BugExcerpt
 
 	for _, excerpt := range c.bugExcerpts {
-		if matcher.Match(excerpt, c) {
+		if matcher(excerpt, c) {
 			filtered = append(filtered, excerpt)
 		}
 	}
@@ -271,6 +276,8 @@ func (c *RepoCache) QueryBugs(q *query.Query) []entity.Id {
 		sorter = BugsByCreationTime(filtered)
 	case query.OrderByEdit:
 		sorter = BugsByEditTime(filtered)
+	case query.OrderByMetadata:
+		sorter = BugsByMetadata{Bugs: filtered, Key: q.OrderKey}
 	default:
 		panic("missing sort type")
 	}
@@ -295,6 +302,12 @@ func (c *RepoCache) QueryBugs(q *query.Query) []entity.Id {
 	return result
 }
 
+// bugContainsWord returns true if the bug contains the word, for the filters.
+// The bug lock must be held.
+func (c *RepoCache) bugContainsWord(id entity.Id, word string) bool {
+	return c.searchIndex.contains(id, word)
+}
+
 // AllBugsIds return all known bug ids
 func (c *RepoCache) AllBugsIds() []entity.Id {
 	c.muBug.RLock()
@@ -331,12 +344,12 @@ func (c *RepoCache) ValidLabels() []bug.Label {
 	}
 
 	// all available workflow labels
-	for _, wf := range bug.GetWorkflowLabels() {
+	for _, wf := range c.configs.WorkflowLabels() {
 		set[wf] = nil
 	}
 
 	// all available checklist labels
-	for _, cl := range bug.GetChecklistLabels() {
+	for _, cl := range c.configs.ChecklistLabels() {
 		set[cl] = nil
 	}
 
@@ -415,23 +428,26 @@ func (c *RepoCache) NewBugRaw(author *IdentityCache, unixTime int64, title strin
 
 // RemoveBug removes a bug from the cache and repo given a bug id prefix
 func (c *RepoCache) RemoveBug(prefix string) error {
-	c.muBug.RLock()
-
+	// ResolveBugPrefix takes the lock itself, and the write lock if the bug
+	// needs to be loaded
 	b, err := c.ResolveBugPrefix(prefix)
 	if err != nil {
-		c.muBug.RUnlock()
 		return err
 	}
-	c.muBug.RUnlock()
 
 	c.muBug.Lock()
 	err = bug.RemoveBug(c.repo, b.Id())
 
 	delete(c.bugs, b.Id())
 	delete(c.bugExcerpts, b.Id())
+	c.searchIndex.remove(b.Id())
 	c.loadedBugs.Remove(b.Id())
 
 	c.muBug.Unlock()
 
-	return c.writeBugCache()
+	err = c.writeBugCache()
+	if err != nil {
+		return err
+	}
+	return c.writeSearchIndex()
 }
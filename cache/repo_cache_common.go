@@ -125,6 +125,7 @@ func (c *RepoCache) MergeAll(remote string) <-chan entity.MergeResult {
 				snap := b.Compile()
 				c.muBug.Lock()
 				c.bugExcerpts[result.Id] = NewBugExcerpt(b, &snap)
+				c.searchIndex.update(result.Id, &snap)
 				c.muBug.Unlock()
 			}
 		}
@@ -142,6 +143,11 @@ func (c *RepoCache) MergeAll(remote string) <-chan entity.MergeResult {
 
 // UpdateConfigs will update all the configs from the remote
 func (c *RepoCache) UpdateConfigs(remote string) (string, error) {
+	c.muConfig.Lock()
+	defer c.muConfig.Unlock()
+
+	defer c.configs.Invalidate()
+
 	return config.UpdateConfigs(c.repo, remote)
 }
 
@@ -165,7 +171,7 @@ func (c *RepoCache) Push(remote string) (string, error) {
 	return "IDENTITIES\n" + stdout1 + "\nTICKETS\n" + stdout2 + "\nCONFIGS\n" + stdout3, nil
 }
 
-// Pull will do a Fetch + MergeAll
+// Pull will do a Fetch + UpdateConfigs + MergeAll
 // This function will return an error if a merge fail
 func (c *RepoCache) Pull(remote string) error {
 	_, err := c.Fetch(remote)
@@ -173,6 +179,11 @@ func (c *RepoCache) Pull(remote string) error {
 		return err
 	}
 
+	_, err = c.UpdateConfigs(remote)
+	if err != nil {
+		return err
+	}
+
 	for merge := range c.MergeAll(remote) {
 		if merge.Err != nil {
 			return merge.Err
@@ -182,11 +193,6 @@ func (c *RepoCache) Pull(remote string) error {
 		}
 	}
 
-	_, err = c.UpdateConfigs(remote)
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -268,9 +274,16 @@ func (c *RepoCache) SetConfig(name string, configData []byte) error {
 	c.muConfig.Lock()
 	defer c.muConfig.Unlock()
 
+	defer c.configs.Invalidate()
+
 	return config.SetConfig(c.repo, name, configData)
 }
 
+// Configs returns the workflows, checklists and groups configured in the repo
+func (c *RepoCache) Configs() *bug.ConfigCache {
+	return c.configs
+}
+
 // Get the named configuration data
 func (c *RepoCache) GetConfig(name string) ([]byte, error) {
 	c.muConfig.RLock()
@@ -156,6 +156,40 @@ func (c *RepoCache) ResolveIdentityPhabID(phabID string) (*IdentityCache, error)
 	})
 }
 
+// ResolveIdentityEmail retrieve an Identity matching the given email.
+// It fails if multiple identities match.
+func (c *RepoCache) ResolveIdentityEmail(email string) (*IdentityCache, error) {
+	return c.ResolveIdentityMatcher(func(excerpt *IdentityExcerpt) bool {
+		return strings.EqualFold(excerpt.Email, email)
+	})
+}
+
+// ResolveIdentityReviewer retrieve the Identity of the author of a review
+// update, from their Phabricator ID or, for the other review providers, their
+// email.
+func (c *RepoCache) ResolveIdentityReviewer(user string) (*IdentityCache, error) {
+	return c.ResolveIdentityMatcher(reviewerMatcher(user))
+}
+
+// ResolveIdentityExcerptReviewer retrieve the IdentityExcerpt of the author of
+// a review update, as ResolveIdentityReviewer does.
+func (c *RepoCache) ResolveIdentityExcerptReviewer(user string) (*IdentityExcerpt, error) {
+	return c.ResolveIdentityExcerptMatcher(reviewerMatcher(user))
+}
+
+// reviewerMatcher matches the identity of a review system user, from their
+// Phabricator ID or their email
+func reviewerMatcher(user string) func(*IdentityExcerpt) bool {
+	if strings.Contains(user, "@") {
+		return func(excerpt *IdentityExcerpt) bool {
+			return strings.EqualFold(excerpt.Email, user)
+		}
+	}
+	return func(excerpt *IdentityExcerpt) bool {
+		return excerpt.PhabID == user
+	}
+}
+
 // ResolveIdentityPrefix retrieve an Identity matching an id prefix.
 // It fails if multiple identities match.
 func (c *RepoCache) ResolveIdentityPrefix(prefix string) (*IdentityCache, error) {
@@ -294,11 +328,40 @@ func (c *RepoCache) UpdateIdentity(i *IdentityCache, name string, email string,
 	return c.identityUpdated(i.Id())
 }
 
+// LookupPhabID queries Phabricator for the ID of the user with the given email,
+// empty if there is no such user
+func (c *RepoCache) LookupPhabID(email string) (string, error) {
+	return c.getPhabId(email)
+}
+
+// SetIdentityPhabID changes the Phabricator ID of an existing identity
+func (c *RepoCache) SetIdentityPhabID(i *IdentityCache, phabID string) error {
+	err := i.Mutate(func(mutator identity.Mutator) identity.Mutator {
+		mutator.PhabID = phabID
+		return mutator
+	})
+	if err != nil {
+		return err
+	}
+
+	err = i.CommitAsNeeded()
+	if err != nil {
+		return err
+	}
+
+	return c.identityUpdated(i.Id())
+}
+
 func (c *RepoCache) getPhabId(email string) (string, error) {
+	at := strings.Index(email, "@")
+	if at < 0 {
+		return "", fmt.Errorf("invalid email %s", email)
+	}
+
 	// Assuming that the e-mail prefix is username on Phabricator
-	user := email[0:strings.Index(email, "@")]
+	user := email[0:at]
 
-	phabClient, err := repository.GetPhabClient()
+	phabClient, err := c.phabClient()
 	if err != nil {
 		return "", err
 	}
@@ -310,8 +373,9 @@ func (c *RepoCache) getPhabId(email string) (string, error) {
 		return "", err
 	}
 
+	// Not every identity is a Phabricator user
 	if len(response.Data) == 0 {
-		return "", fmt.Errorf("no Phabricator users matching %s", user)
+		return "", nil
 	}
 
 	return response.Data[0].PHID, nil
@@ -1,12 +1,16 @@
 package cache
 
 import (
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/thought-machine/gonduit"
 
 	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/entity"
 	"github.com/daedaleanai/git-ticket/query"
 	"github.com/daedaleanai/git-ticket/repository"
 )
@@ -280,6 +284,46 @@ func TestRemove(t *testing.T) {
 	assert.Error(t, bug.ErrBugNotExist, err)
 }
 
+// Removing a bug which isn't loaded yet used to deadlock, the bug being loaded
+// under the write lock while the read lock was held
+func TestRemoveNotLoaded(t *testing.T) {
+	repo := repository.CreateTestRepo(false)
+	defer repository.CleanupTestRepos(repo)
+
+	repository.SetupSigningKey(t, repo, "a@e.org")
+
+	repoCache, err := NewRepoCache(repo)
+	require.NoError(t, err)
+
+	rene, err := repoCache.NewIdentity("René Descartes", "rene@descartes.fr")
+	require.NoError(t, err)
+	require.NoError(t, repoCache.SetUserIdentity(rene))
+
+	b1, _, err := repoCache.NewBug("title", "message")
+	require.NoError(t, err)
+
+	// Start over with no bug loaded
+	require.NoError(t, repoCache.Close())
+	repoCache, err = NewRepoCache(repo)
+	require.NoError(t, err)
+	require.Empty(t, repoCache.bugs)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- repoCache.RemoveBug(b1.Id().String())
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("RemoveBug deadlocked")
+	}
+
+	_, err = repoCache.ResolveBug(b1.Id())
+	assert.Equal(t, bug.ErrBugNotExist, err)
+}
+
 func TestCacheEviction(t *testing.T) {
 	repo := repository.CreateTestRepo(false)
 	repository.SetupSigningKey(t, repo, "a@e.org")
@@ -343,3 +387,341 @@ func checkBugPresence(t *testing.T, cache *RepoCache, bug *BugCache, presence bo
 		require.Equal(t, bug, b)
 	}
 }
+
+func TestCacheConfigs(t *testing.T) {
+	repo := repository.CreateTestRepo(false)
+	defer repository.CleanupTestRepos(repo)
+
+	repository.SetupSigningKey(t, repo, "a@e.org")
+
+	cache, err := NewRepoCache(repo)
+	require.NoError(t, err)
+
+	require.NotNil(t, cache.Configs().FindWorkflow("workflow:eng"))
+
+	err = cache.SetConfig("workflows", []byte(`{
+		"workflow:hw": {
+			"initialState": "proposed",
+			"transitions": [{"start": "proposed", "end": "done"}]
+		}
+	}`))
+	require.NoError(t, err)
+
+	// Setting a config invalidates the workflows read so far
+	require.Nil(t, cache.Configs().FindWorkflow("workflow:eng"))
+	require.NotNil(t, cache.Configs().FindWorkflow("workflow:hw"))
+	require.Contains(t, cache.ValidLabels(), bug.Label("workflow:hw"))
+}
+
+func TestCachePhabricatorReview(t *testing.T) {
+	repo := repository.CreateTestRepo(false)
+	defer repository.CleanupTestRepos(repo)
+
+	repository.SetupSigningKey(t, repo, "alice@example.com")
+
+	fake := repository.NewFakeConduit(repository.ConduitFixtures())
+	defer fake.Close()
+
+	cache, err := NewRepoCache(repo)
+	require.NoError(t, err)
+	cache.SetPhabricator(fake.URL, repository.FakeConduitToken)
+
+	// The Phabricator ids of the identities are looked up from their email
+	alice, err := cache.NewIdentity("Alice", "alice@example.com")
+	require.NoError(t, err)
+	require.NoError(t, cache.SetUserIdentity(alice))
+	require.Equal(t, "PHID-USER-alice", alice.PhabID())
+
+	bob, err := cache.NewIdentity("Bob", "bob@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "PHID-USER-bob", bob.PhabID())
+
+	b, _, err := cache.NewBug("title", "message")
+	require.NoError(t, err)
+
+	// The reviews are fetched from the Phabricator server of the cache
+	providers, err := cache.ReviewProviders()
+	require.NoError(t, err)
+	review, err := providers.FetchReviewInfo("D1234", "")
+	require.NoError(t, err)
+
+	_, err = b.SetReview(review)
+	require.NoError(t, err)
+	require.NoError(t, b.Commit())
+
+	stored := b.Snapshot().Reviews["D1234"]
+	require.Equal(t, "Add the frobnicator", stored.Title)
+	require.True(t, stored.IsAccepted())
+	for _, u := range stored.Updates {
+		expected := bob.Id()
+		if u.PhabUser == "PHID-USER-alice" {
+			expected = alice.Id()
+		}
+		require.Equal(t, expected, u.Author.Id())
+	}
+
+	// The reviews are summarized in the excerpt for the queries
+	for q, expected := range map[string]int{
+		"review:accepted": 1,
+		"review:none":     0,
+		"reviewer:bob":    1,
+		"reviewer:alice":  0,
+		"review:accepted -review:none reviewer:Bob": 1,
+	} {
+		parsed, err := query.Parse(q)
+		require.NoError(t, err)
+		require.Len(t, cache.QueryBugs(parsed), expected, q)
+	}
+}
+
+func TestCacheSetIdentityPhabID(t *testing.T) {
+	repo := repository.CreateTestRepo(false)
+	defer repository.CleanupTestRepos(repo)
+
+	repository.SetupSigningKey(t, repo, "bob@example.com")
+
+	fake := repository.NewFakeConduit(repository.ConduitFixtures())
+	defer fake.Close()
+
+	cache, err := NewRepoCache(repo)
+	require.NoError(t, err)
+
+	// Phabricator can't be queried when the identity is created
+	cache.SetPhabricator(fake.URL, "api-wrong")
+	bob, err := cache.NewIdentity("Bob", "bob@example.com")
+	require.NoError(t, err)
+	require.NoError(t, cache.SetUserIdentity(bob))
+	require.Empty(t, bob.PhabID())
+
+	_, err = cache.ResolveIdentityPhabID("PHID-USER-bob")
+	require.Error(t, err)
+
+	// The review users without identity are stored as is
+	provider := bug.NewPhabricatorProvider(func() (*gonduit.Conn, error) {
+		return repository.GetPhabClient(fake.URL, repository.FakeConduitToken)
+	})
+	review, err := provider.FetchReviewInfo("D1234", "")
+	require.NoError(t, err)
+
+	b, _, err := cache.NewBug("title", "message")
+	require.NoError(t, err)
+	_, err = b.SetReview(review)
+	require.NoError(t, err)
+	require.NoError(t, b.Commit())
+
+	require.NoError(t, cache.Close())
+	cache, err = NewRepoCache(repo)
+	require.NoError(t, err)
+
+	b, err = cache.ResolveBug(b.Id())
+	require.NoError(t, err)
+	for _, u := range b.Snapshot().Reviews["D1234"].Updates {
+		require.Nil(t, u.Author)
+	}
+
+	bob, err = cache.ResolveIdentity(bob.Id())
+	require.NoError(t, err)
+
+	cache.SetPhabricator(fake.URL, repository.FakeConduitToken)
+	phabID, err := cache.LookupPhabID("carol@example.com")
+	require.NoError(t, err)
+	require.Empty(t, phabID)
+
+	phabID, err = cache.LookupPhabID(bob.Email())
+	require.NoError(t, err)
+	require.Equal(t, "PHID-USER-bob", phabID)
+
+	_, err = cache.LookupPhabID("bob")
+	require.Error(t, err)
+
+	byBob, err := query.Parse("reviewer:" + bob.Id().String())
+	require.NoError(t, err)
+	require.Empty(t, cache.QueryBugs(byBob))
+
+	require.NoError(t, cache.SetIdentityPhabID(bob, phabID))
+
+	resolved, err := cache.ResolveIdentityPhabID("PHID-USER-bob")
+	require.NoError(t, err)
+	require.Equal(t, bob.Id(), resolved.Id())
+
+	// The reviews stored before are found from the identity matched since
+	require.Equal(t, []entity.Id{b.Id()}, cache.QueryBugs(byBob))
+}
+
+func TestCacheSearch(t *testing.T) {
+	repo := repository.CreateTestRepo(false)
+	defer repository.CleanupTestRepos(repo)
+
+	repository.SetupSigningKey(t, repo, "alice@example.com")
+
+	fake := repository.NewFakeConduit(repository.ConduitFixtures())
+	defer fake.Close()
+
+	cache, err := NewRepoCache(repo)
+	require.NoError(t, err)
+	cache.SetPhabricator(fake.URL, repository.FakeConduitToken)
+
+	alice, err := cache.NewIdentity("Alice", "alice@example.com")
+	require.NoError(t, err)
+	require.NoError(t, cache.SetUserIdentity(alice))
+
+	watchdog, _, err := cache.NewBug("Watchdog fires on boot", "The timeout is too short.")
+	require.NoError(t, err)
+	frob, _, err := cache.NewBug("Frobnicator", "Widgets aren't frobnicated")
+	require.NoError(t, err)
+
+	search := func(q string) []entity.Id {
+		parsed, err := query.Parse(q + " sort:id")
+		require.NoError(t, err)
+		return cache.QueryBugs(parsed)
+	}
+	ids := func(bugs ...*BugCache) []entity.Id {
+		var result []entity.Id
+		for _, b := range bugs {
+			result = append(result, b.Id())
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+		return result
+	}
+
+	require.Equal(t, ids(watchdog), search("watchdog"))
+	require.Equal(t, ids(watchdog), search(`"Watchdog timeout"`))
+	require.Empty(t, search("watchdog frobnicated"))
+	require.Equal(t, ids(watchdog, frob), search("watchdog OR widgets"))
+	require.Equal(t, ids(frob), search("-watchdog"))
+
+	// The index is updated with the comments and the reviews
+	_, err = frob.AddComment("Seen again after the watchdog reset")
+	require.NoError(t, err)
+	require.NoError(t, frob.Commit())
+	require.Equal(t, ids(watchdog, frob), search("watchdog"))
+
+	provider := bug.NewPhabricatorProvider(func() (*gonduit.Conn, error) {
+		return repository.GetPhabClient(fake.URL, repository.FakeConduitToken)
+	})
+	review, err := provider.FetchReviewInfo("D1234", "")
+	require.NoError(t, err)
+	_, err = frob.SetReview(review)
+	require.NoError(t, err)
+	require.NoError(t, frob.Commit())
+	require.Equal(t, ids(frob), search("off by one"))
+
+	// The index is stored with the cache
+	require.NoError(t, cache.Close())
+	cache, err = NewRepoCache(repo)
+	require.NoError(t, err)
+	require.Equal(t, ids(frob), search("off by one"))
+	require.Equal(t, ids(watchdog, frob), search("watchdog"))
+
+	require.NoError(t, cache.RemoveBug(frob.Id().String()))
+	require.Equal(t, ids(watchdog), search("watchdog"))
+	require.Empty(t, search("off"))
+}
+
+func TestCacheMetadata(t *testing.T) {
+	repo := repository.CreateTestRepo(false)
+	defer repository.CleanupTestRepos(repo)
+
+	repository.SetupSigningKey(t, repo, "alice@example.com")
+
+	cache, err := NewRepoCache(repo)
+	require.NoError(t, err)
+
+	alice, err := cache.NewIdentity("Alice", "alice@example.com")
+	require.NoError(t, err)
+	require.NoError(t, cache.SetUserIdentity(alice))
+
+	imported := make([]*BugCache, 2)
+	for i, key := range []string{"HW-2", "HW-1"} {
+		imported[i], _, err = cache.NewBugRaw(alice, time.Now().Unix(), "imported", "message", nil,
+			map[string]string{"origin": "jira", "jira-key": key})
+		require.NoError(t, err)
+	}
+
+	// The metadata set on the other operations are queried as well
+	local, _, err := cache.NewBug("local", "message")
+	require.NoError(t, err)
+	op, err := local.AddComment("comment")
+	require.NoError(t, err)
+	_, err = local.SetMetadata(op.Id(), map[string]string{"reviewed-by": "bot"})
+	require.NoError(t, err)
+	require.NoError(t, local.Commit())
+
+	search := func(q string) []entity.Id {
+		parsed, err := query.Parse(q)
+		require.NoError(t, err)
+		return cache.QueryBugs(parsed)
+	}
+
+	require.Equal(t, []entity.Id{imported[1].Id(), imported[0].Id()}, search("metadata:origin=jira sort:metadata.jira-key"))
+	require.Equal(t, []entity.Id{imported[0].Id(), imported[1].Id()}, search("metadata:origin sort:metadata.jira-key-desc"))
+	require.Equal(t, []entity.Id{imported[0].Id()}, search("metadata:jira-key=HW-2"))
+	require.Equal(t, []entity.Id{local.Id()}, search("metadata:reviewed-by=bot"))
+	require.Equal(t, []entity.Id{local.Id()}, search("-metadata:origin"))
+
+	// The bugs without the metadata come first
+	require.Equal(t, []entity.Id{local.Id(), imported[1].Id(), imported[0].Id()}, search("sort:metadata.jira-key"))
+
+	// The metadata are stored in the cache
+	require.NoError(t, cache.Close())
+	cache, err = NewRepoCache(repo)
+	require.NoError(t, err)
+	require.Equal(t, []entity.Id{local.Id()}, search("metadata:reviewed-by"))
+}
+
+func TestCacheViews(t *testing.T) {
+	repo := repository.CreateTestRepo(false)
+	defer repository.CleanupTestRepos(repo)
+
+	repository.SetupSigningKey(t, repo, "alice@example.com")
+
+	cache, err := NewRepoCache(repo)
+	require.NoError(t, err)
+
+	alice, err := cache.NewIdentity("Alice", "alice@example.com")
+	require.NoError(t, err)
+	require.NoError(t, cache.SetUserIdentity(alice))
+
+	hw, _, err := cache.NewBug("hardware", "message")
+	require.NoError(t, err)
+	_, err = hw.ForceChangeLabels([]string{"hw"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, hw.Commit())
+
+	sw, _, err := cache.NewBug("software", "message")
+	require.NoError(t, err)
+
+	require.NoError(t, cache.SetView("HW", "label:hw", true))
+	require.NoError(t, cache.SetView("mine", "author:alice sort:id", false))
+	require.NoError(t, cache.SetView("hw", "label:hw OR label:fpga", false))
+
+	require.Error(t, cache.SetView("1st", "label:hw", false))
+	require.Error(t, cache.SetView("bad", "label:hw OR", true))
+	require.Error(t, cache.SetView("nested", "@hw", false))
+
+	views, err := cache.Views()
+	require.NoError(t, err)
+	require.Equal(t, []View{
+		{Name: "hw", Query: "label:hw OR label:fpga"},
+		{Name: "hw", Query: "label:hw", Shared: true},
+		{Name: "mine", Query: "author:alice sort:id"},
+	}, views)
+
+	search := func(q string) []entity.Id {
+		parsed, err := cache.ParseQuery(q)
+		require.NoError(t, err)
+		return cache.QueryBugs(parsed)
+	}
+
+	require.Equal(t, []entity.Id{hw.Id()}, search("@hw"))
+	require.Equal(t, []entity.Id{sw.Id()}, search("@mine -@hw"))
+
+	// The shared view is used once the local one is removed
+	require.NoError(t, cache.RemoveView("hw", false))
+	require.Error(t, cache.RemoveView("hw", false))
+	require.Equal(t, []entity.Id{hw.Id()}, search("@hw"))
+
+	require.NoError(t, cache.RemoveView("hw", true))
+	_, err = cache.ParseQuery("@hw")
+	require.Error(t, err)
+}
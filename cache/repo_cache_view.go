@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/daedaleanai/git-ticket/config"
+	"github.com/daedaleanai/git-ticket/query"
+)
+
+// viewConfigKeyPrefix is the prefix of the git config keys of the views saved
+// locally, holding their query
+const viewConfigKeyPrefix = "daedalean.view."
+
+// viewsConfigName is the name of the config holding the views shared with the
+// team, a JSON map of the view names to their query
+const viewsConfigName = "views"
+
+// The view names are valid git config variable names, which are case
+// insensitive
+var viewNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// View is a saved query, referenced as @name in the queries
+type View struct {
+	Name  string
+	Query string
+	// true if the view is shared with the team rather than saved locally
+	Shared bool
+}
+
+// ParseViews parses and validates the config of the shared views
+func ParseViews(data []byte) (map[string]string, error) {
+	views := make(map[string]string)
+
+	if err := json.Unmarshal(data, &views); err != nil {
+		return nil, err
+	}
+
+	for name := range views {
+		if !viewNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid view name %q", name)
+		}
+	}
+
+	return views, nil
+}
+
+func validateViewName(name string) error {
+	if !viewNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid view name %q, expected a letter followed by letters, digits or dashes", name)
+	}
+	return nil
+}
+
+func (c *RepoCache) localViews() (map[string]string, error) {
+	entries, err := c.repo.LocalConfig().ReadAll(viewConfigKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make(map[string]string, len(entries))
+	for key, q := range entries {
+		views[strings.ToLower(strings.TrimPrefix(key, viewConfigKeyPrefix))] = q
+	}
+	return views, nil
+}
+
+func (c *RepoCache) sharedViews() (map[string]string, error) {
+	data, err := c.GetConfig(viewsConfigName)
+	if err == config.ErrConfigNotFound {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := ParseViews(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s config: %s", viewsConfigName, err)
+	}
+	return views, nil
+}
+
+// Views returns the views saved locally and the ones shared with the team,
+// sorted by name, the local one first when both have the same name
+func (c *RepoCache) Views() ([]View, error) {
+	local, err := c.localViews()
+	if err != nil {
+		return nil, err
+	}
+	shared, err := c.sharedViews()
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]View, 0, len(local)+len(shared))
+	for name, q := range local {
+		views = append(views, View{Name: name, Query: q})
+	}
+	for name, q := range shared {
+		views = append(views, View{Name: name, Query: q, Shared: true})
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Name != views[j].Name {
+			return views[i].Name < views[j].Name
+		}
+		return !views[i].Shared
+	})
+
+	return views, nil
+}
+
+// ViewQueries returns the query of each view by name, the views saved locally
+// taking precedence over the shared ones
+func (c *RepoCache) ViewQueries() (map[string]string, error) {
+	views, err := c.sharedViews()
+	if err != nil {
+		return nil, err
+	}
+	local, err := c.localViews()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, q := range local {
+		views[name] = q
+	}
+	return views, nil
+}
+
+// ParseQuery parses a query, accepting the statuses of the workflows of the
+// repository and expanding the views it references
+func (c *RepoCache) ParseQuery(q string) (*query.Query, error) {
+	views, err := c.ViewQueries()
+	if err != nil {
+		return nil, err
+	}
+	return query.ParseWithViews(q, c.Configs(), views)
+}
+
+// SetView saves a view locally, or shares it with the team. The query is
+// validated first, it can't reference other views.
+func (c *RepoCache) SetView(name, q string, shared bool) error {
+	name = strings.ToLower(name)
+	if err := validateViewName(name); err != nil {
+		return err
+	}
+	if _, err := query.ParseWithConfigs(q, c.Configs()); err != nil {
+		return fmt.Errorf("invalid query for view %s: %s", name, err)
+	}
+
+	if !shared {
+		return c.repo.LocalConfig().StoreString(viewConfigKeyPrefix+name, q)
+	}
+
+	views, err := c.sharedViews()
+	if err != nil {
+		return err
+	}
+	views[name] = q
+	return c.setSharedViews(views)
+}
+
+// RemoveView removes a view saved locally, or shared with the team
+func (c *RepoCache) RemoveView(name string, shared bool) error {
+	name = strings.ToLower(name)
+
+	if !shared {
+		local, err := c.localViews()
+		if err != nil {
+			return err
+		}
+		if _, ok := local[name]; !ok {
+			return fmt.Errorf("no local view %s", name)
+		}
+		return c.repo.LocalConfig().RemoveAll(viewConfigKeyPrefix + name)
+	}
+
+	views, err := c.sharedViews()
+	if err != nil {
+		return err
+	}
+	if _, ok := views[name]; !ok {
+		return fmt.Errorf("no shared view %s", name)
+	}
+	delete(views, name)
+	return c.setSharedViews(views)
+}
+
+func (c *RepoCache) setSharedViews(views map[string]string) error {
+	data, err := json.MarshalIndent(views, "", "  ")
+	if err != nil {
+		return err
+	}
+	return c.SetConfig(viewsConfigName, data)
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/repository"
+)
+
+const searchIndexFile = "search-index"
+
+func searchIndexFilePath(repo repository.Repo) string {
+	return path.Join(repo.GetPath(), "git-bug", searchIndexFile)
+}
+
+// searchIndex is an inverted index of the words of the bugs: their title, the
+// message of their comments and the text of the comments of their reviews.
+// Only the words are stored on disk, the words of each bug are rebuilt from
+// them to update the index when a bug changes.
+type searchIndex struct {
+	// the bugs containing each word
+	words map[string]map[entity.Id]struct{}
+	// the words of each bug
+	bugs map[entity.Id][]string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		words: make(map[string]map[entity.Id]struct{}),
+		bugs:  make(map[entity.Id][]string),
+	}
+}
+
+// searchWords splits a text into the lowercase words that are indexed and
+// searched
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// bugSearchWords returns the distinct words of a bug, sorted
+func bugSearchWords(snap *bug.Snapshot) []string {
+	set := make(map[string]struct{})
+	add := func(text string) {
+		for _, w := range searchWords(text) {
+			set[w] = struct{}{}
+		}
+	}
+
+	add(snap.Title)
+	for _, c := range snap.Comments {
+		add(c.Message)
+	}
+	for _, review := range snap.Reviews {
+		for _, u := range review.Updates {
+			if u.Type == bug.CommentTransaction {
+				add(u.Text)
+			}
+		}
+	}
+
+	words := make([]string, 0, len(set))
+	for w := range set {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// update replaces the words of a bug in the index
+func (idx *searchIndex) update(id entity.Id, snap *bug.Snapshot) {
+	idx.remove(id)
+
+	words := bugSearchWords(snap)
+	for _, w := range words {
+		bugs, ok := idx.words[w]
+		if !ok {
+			bugs = make(map[entity.Id]struct{})
+			idx.words[w] = bugs
+		}
+		bugs[id] = struct{}{}
+	}
+	idx.bugs[id] = words
+}
+
+// remove removes a bug from the index
+func (idx *searchIndex) remove(id entity.Id) {
+	for _, w := range idx.bugs[id] {
+		delete(idx.words[w], id)
+		if len(idx.words[w]) == 0 {
+			delete(idx.words, w)
+		}
+	}
+	delete(idx.bugs, id)
+}
+
+// contains returns true if the bug contains the word
+func (idx *searchIndex) contains(id entity.Id, word string) bool {
+	_, ok := idx.words[word][id]
+	return ok
+}
+
+// loadSearchIndex will try to read from the disk the search index file
+func (c *RepoCache) loadSearchIndex() error {
+	c.muBug.Lock()
+	defer c.muBug.Unlock()
+
+	f, err := os.Open(searchIndexFilePath(c.repo))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := gob.NewDecoder(f)
+
+	aux := struct {
+		Version uint
+		Words   map[string][]entity.Id
+	}{}
+
+	err = decoder.Decode(&aux)
+	if err != nil {
+		return err
+	}
+
+	if aux.Version != formatVersion {
+		return fmt.Errorf("unknown search index format version %v", aux.Version)
+	}
+
+	idx := newSearchIndex()
+	for w, ids := range aux.Words {
+		bugs := make(map[entity.Id]struct{}, len(ids))
+		for _, id := range ids {
+			bugs[id] = struct{}{}
+			idx.bugs[id] = append(idx.bugs[id], w)
+		}
+		idx.words[w] = bugs
+	}
+
+	c.searchIndex = idx
+	return nil
+}
+
+// writeSearchIndex will serialize on disk the search index file
+func (c *RepoCache) writeSearchIndex() error {
+	c.muBug.RLock()
+	defer c.muBug.RUnlock()
+
+	var data bytes.Buffer
+
+	aux := struct {
+		Version uint
+		Words   map[string][]entity.Id
+	}{
+		Version: formatVersion,
+		Words:   make(map[string][]entity.Id, len(c.searchIndex.words)),
+	}
+
+	for w, bugs := range c.searchIndex.words {
+		ids := make([]entity.Id, 0, len(bugs))
+		for id := range bugs {
+			ids = append(ids, id)
+		}
+		aux.Words[w] = ids
+	}
+
+	encoder := gob.NewEncoder(&data)
+
+	err := encoder.Encode(aux)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(searchIndexFilePath(c.repo))
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/entity"
+)
+
+func TestSearchWords(t *testing.T) {
+	assert.Equal(t, []string{"watchdog", "timeout", "in", "fw", "2", "3", "é", "tude"},
+		searchWords("Watchdog-timeout in FW_2.3 (é-tude)!"))
+	assert.Empty(t, searchWords(" -- "))
+}
+
+func TestSearchIndex(t *testing.T) {
+	idx := newSearchIndex()
+
+	snap := &bug.Snapshot{
+		Title:    "Watchdog fires",
+		Comments: []bug.Comment{{Message: "On boot, the watchdog times out"}},
+		Reviews: map[string]bug.ReviewInfo{
+			"D1": {Title: "Not indexed", Updates: []bug.ReviewUpdate{
+				{PhabTransaction: bug.PhabTransaction{Type: bug.CommentTransaction, Text: "Off by one"}},
+				{PhabTransaction: bug.PhabTransaction{Type: bug.StatusTransaction, Status: "accepted"}},
+			}},
+		},
+	}
+	assert.Equal(t, []string{"boot", "by", "fires", "off", "on", "one", "out", "the", "times", "watchdog"},
+		bugSearchWords(snap))
+
+	idx.update("a", snap)
+	idx.update("b", &bug.Snapshot{Title: "Watchdog"})
+	assert.True(t, idx.contains("a", "off"))
+	assert.True(t, idx.contains("b", "watchdog"))
+	assert.False(t, idx.contains("a", "accepted"))
+	assert.False(t, idx.contains("a", "indexed"))
+
+	// The words removed from a bug are removed from the index
+	idx.update("a", &bug.Snapshot{Title: "Watchdog"})
+	assert.False(t, idx.contains("a", "off"))
+	assert.NotContains(t, idx.words, "off")
+	assert.Equal(t, map[entity.Id]struct{}{"a": {}, "b": {}}, idx.words["watchdog"])
+
+	idx.remove("a")
+	idx.remove("b")
+	assert.Empty(t, idx.words)
+	assert.Empty(t, idx.bugs)
+}
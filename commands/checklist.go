@@ -0,0 +1,16 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newChecklistCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "checklist",
+		Short: "Report on the checklists of the tickets.",
+	}
+
+	cmd.AddCommand(newChecklistReportCommand())
+
+	return cmd
+}
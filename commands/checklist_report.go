@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	text "github.com/MichaelMure/go-term-text"
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+type checklistReportOptions struct {
+	checklists   []string
+	outputFormat string
+}
+
+func newChecklistReportCommand() *cobra.Command {
+	env := newEnv()
+	options := checklistReportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "report [QUERY]",
+		Short: "Summarize the answers to the checklists of the tickets.",
+		Long: `Count the PASSED, FAILED, NA and TBD answers to each checklist question, per reviewer, across the tickets matching the query, or all tickets if there is none.
+
+Checklists nobody answered yet are counted as TBD, with no reviewer.`,
+		Example: `List the questions of the code checklist failed or left TBD in merged tickets:
+git ticket checklist report --checklist checklist:code status:merged
+
+Export the report of all the tickets for a spreadsheet:
+git ticket checklist report --format csv > report.csv
+`,
+		PreRunE:  loadBackend(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runChecklistReport(env, options, args)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.StringSliceVarP(&options.checklists, "checklist", "c", nil,
+		"Only report on the given checklists, e.g. checklist:code")
+	flags.StringVarP(&options.outputFormat, "format", "f", "default",
+		"Select the output formatting style. Valid values are [default,json,csv]")
+
+	return cmd
+}
+
+func runChecklistReport(env *Env, opts checklistReportOptions, args []string) error {
+	q, err := env.backend.ParseQuery(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	var only []bug.Label
+	for _, c := range opts.checklists {
+		l := bug.Label(c)
+		if !l.IsChecklist() {
+			l = bug.Label("checklist:" + c)
+		}
+		only = append(only, l)
+	}
+
+	report := bug.NewChecklistReport()
+
+	for _, id := range env.backend.QueryBugs(q) {
+		b, err := env.backend.ResolveBug(id)
+		if err != nil {
+			return err
+		}
+		report.Add(env.backend.Configs(), b.Snapshot(), only...)
+	}
+
+	switch opts.outputFormat {
+	case "json":
+		return checklistReportJsonFormatter(env, report)
+	case "csv":
+		return checklistReportCsvFormatter(env, report)
+	case "default":
+		return checklistReportDefaultFormatter(env, report)
+	default:
+		return fmt.Errorf("unknown format %s", opts.outputFormat)
+	}
+}
+
+// reviewerName returns the display name of the reviewer of a checklist
+func reviewerName(env *Env, reviewer entity.Id) string {
+	if reviewer == "" {
+		return "<nobody>"
+	}
+
+	excerpt, err := env.backend.ResolveIdentityExcerpt(reviewer)
+	if err != nil {
+		return reviewer.Human()
+	}
+	return excerpt.DisplayName()
+}
+
+func humanIds(ids []entity.Id) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = id.Human()
+	}
+	return result
+}
+
+func checklistReportDefaultFormatter(env *Env, report *bug.ChecklistReport) error {
+	env.out.Printf("%s %s %s %6s %6s %4s %4s  %s\n",
+		text.LeftPadMaxLine("CHECKLIST", 20, 0),
+		text.LeftPadMaxLine("QUESTION", 50, 0),
+		text.LeftPadMaxLine("REVIEWER", 20, 0),
+		"PASSED", "FAILED", "NA", "TBD", "TICKETS")
+
+	for _, qr := range report.Questions() {
+		var tickets []string
+		for _, id := range humanIds(qr.FailedTickets) {
+			tickets = append(tickets, colors.Red(id))
+		}
+		for _, id := range humanIds(qr.TBDTickets) {
+			tickets = append(tickets, colors.Blue(id))
+		}
+
+		env.out.Printf("%s %s %s %6d %6d %4d %4d  %s\n",
+			text.LeftPadMaxLine(qr.Checklist.String(), 20, 0),
+			text.LeftPadMaxLine(qr.Section+": "+qr.Question, 50, 0),
+			colors.Magenta(text.LeftPadMaxLine(reviewerName(env, qr.Reviewer), 20, 0)),
+			qr.Passed, qr.Failed, qr.NotApplicable, qr.TBD,
+			strings.Join(tickets, " "),
+		)
+	}
+
+	return nil
+}
+
+type JSONChecklistQuestionReport struct {
+	Checklist     bug.Label     `json:"checklist"`
+	Section       string        `json:"section"`
+	Question      string        `json:"question"`
+	Reviewer      *JSONIdentity `json:"reviewer"`
+	Passed        int           `json:"passed"`
+	Failed        int           `json:"failed"`
+	NotApplicable int           `json:"na"`
+	TBD           int           `json:"tbd"`
+	FailedTickets []string      `json:"failed_tickets"`
+	TBDTickets    []string      `json:"tbd_tickets"`
+}
+
+func checklistReportJsonFormatter(env *Env, report *bug.ChecklistReport) error {
+	questions := report.Questions()
+
+	jsonQuestions := make([]JSONChecklistQuestionReport, len(questions))
+	for i, qr := range questions {
+		jsonQuestion := JSONChecklistQuestionReport{
+			Checklist:     qr.Checklist,
+			Section:       qr.Section,
+			Question:      qr.Question,
+			Passed:        qr.Passed,
+			Failed:        qr.Failed,
+			NotApplicable: qr.NotApplicable,
+			TBD:           qr.TBD,
+			FailedTickets: humanIds(qr.FailedTickets),
+			TBDTickets:    humanIds(qr.TBDTickets),
+		}
+
+		if qr.Reviewer != "" {
+			// A reviewer whose identity isn't known locally is given by id only
+			jsonReviewer := JSONIdentity{Id: qr.Reviewer.String(), HumanId: qr.Reviewer.Human()}
+			if reviewer, err := env.backend.ResolveIdentityExcerpt(qr.Reviewer); err == nil {
+				jsonReviewer = NewJSONIdentityFromExcerpt(reviewer)
+			}
+			jsonQuestion.Reviewer = &jsonReviewer
+		}
+
+		jsonQuestions[i] = jsonQuestion
+	}
+
+	jsonObject, _ := json.MarshalIndent(jsonQuestions, "", "    ")
+	env.out.Printf("%s\n", jsonObject)
+	return nil
+}
+
+func checklistReportCsvFormatter(env *Env, report *bug.ChecklistReport) error {
+	w := csv.NewWriter(env.out)
+
+	err := w.Write([]string{"checklist", "section", "question", "reviewer_id", "reviewer",
+		"passed", "failed", "na", "tbd", "failed_tickets", "tbd_tickets"})
+	if err != nil {
+		return err
+	}
+
+	for _, qr := range report.Questions() {
+		var reviewerId, reviewer string
+		if qr.Reviewer != "" {
+			reviewerId = qr.Reviewer.Human()
+			reviewer = reviewerName(env, qr.Reviewer)
+		}
+
+		err := w.Write([]string{
+			qr.Checklist.String(),
+			qr.Section,
+			qr.Question,
+			reviewerId,
+			reviewer,
+			strconv.Itoa(qr.Passed),
+			strconv.Itoa(qr.Failed),
+			strconv.Itoa(qr.NotApplicable),
+			strconv.Itoa(qr.TBD),
+			strings.Join(humanIds(qr.FailedTickets), " "),
+			strings.Join(humanIds(qr.TBDTickets), " "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
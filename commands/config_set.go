@@ -6,6 +6,8 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/cache"
 	"github.com/daedaleanai/git-ticket/input"
 )
 
@@ -70,5 +72,25 @@ func runConfigSet(env *Env, options configSetOptions, args []string) error {
 		return fmt.Errorf("the config data you specified is not properly formatted: %s", err)
 	}
 
+	// Validate the schema of known configs, so a broken one can't be published
+	switch args[0] {
+	case "workflows":
+		if _, err := bug.ParseWorkflows([]byte(configData)); err != nil {
+			return fmt.Errorf("the workflows config is invalid: %s", err)
+		}
+	case "checklists":
+		if _, err := bug.ParseChecklists([]byte(configData)); err != nil {
+			return fmt.Errorf("the checklists config is invalid: %s", err)
+		}
+	case "groups":
+		if _, err := bug.ParseGroups([]byte(configData)); err != nil {
+			return fmt.Errorf("the groups config is invalid: %s", err)
+		}
+	case "views":
+		if _, err := cache.ParseViews([]byte(configData)); err != nil {
+			return fmt.Errorf("the views config is invalid: %s", err)
+		}
+	}
+
 	return env.backend.SetConfig(args[0], []byte(configData))
 }
@@ -0,0 +1,16 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export tickets as documents.",
+	}
+
+	cmd.AddCommand(newExportEvidenceCommand())
+
+	return cmd
+}
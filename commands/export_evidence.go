@@ -0,0 +1,513 @@
+package commands
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/cache"
+	_select "github.com/daedaleanai/git-ticket/commands/select"
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/identity"
+	"github.com/daedaleanai/git-ticket/validate"
+)
+
+type exportEvidenceOptions struct {
+	format string
+	output string
+}
+
+func newExportEvidenceCommand() *cobra.Command {
+	env := newEnv()
+	options := exportEvidenceOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "evidence [ID]",
+		Short: "Export the checklists and reviews of a ticket as an audit evidence document.",
+		Long: `Export the history, the checklists of every reviewer and the Phabricator reviews of a ticket as a self-contained document.
+
+Each operation of the ticket is listed with the git commit storing it and the fingerprint of the key its signature was verified against, as done by "git ticket validate". Operations whose signature can't be verified are flagged in the document and make the command fail once the document is written.`,
+		Example:  `git ticket export evidence --format html --output evidence.html 29a4526`,
+		PreRunE:  loadBackend(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExportEvidence(env, options, args)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.StringVarP(&options.format, "format", "f", "markdown",
+		"Select the output formatting style. Valid values are [markdown,html]")
+	flags.StringVarP(&options.output, "output", "o", "",
+		"Write the document to the given file instead of the standard output")
+
+	return cmd
+}
+
+func runExportEvidence(env *Env, opts exportEvidenceOptions, args []string) error {
+	var render func(io.Writer, *evidenceDocument) error
+	switch opts.format {
+	case "markdown":
+		render = func(w io.Writer, doc *evidenceDocument) error {
+			return evidenceMarkdownTemplate.Execute(w, doc)
+		}
+	case "html":
+		render = func(w io.Writer, doc *evidenceDocument) error {
+			return evidenceHtmlTemplate.Execute(w, doc)
+		}
+	default:
+		return fmt.Errorf("unknown format %s", opts.format)
+	}
+
+	b, _, err := _select.ResolveBug(env.backend, args)
+	if err != nil {
+		return err
+	}
+
+	validator, err := validate.NewValidator(env.repo, env.backend)
+	if err != nil {
+		return err
+	}
+
+	doc := newEvidenceDocument(env, b, validator)
+
+	var out io.Writer = env.out
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := render(out, doc); err != nil {
+		return err
+	}
+
+	if doc.Unverified > 0 {
+		return errors.Errorf("%d operation(s) of ticket %s failed signature validation", doc.Unverified, b.Id().Human())
+	}
+
+	return nil
+}
+
+// evidenceDocument holds everything rendered in an evidence document
+type evidenceDocument struct {
+	Id          string
+	Title       string
+	Status      string
+	Workflow    string
+	Author      string
+	Assignee    string
+	Labels      []string
+	CreatedAt   string
+	GeneratedAt string
+	FirstKey    string
+
+	Operations []evidenceOperation
+	Checklists []evidenceChecklist
+	Reviews    []evidenceReview
+
+	// Unverified counts the operations whose signature failed validation
+	Unverified int
+}
+
+type evidenceOperation struct {
+	Id      string
+	Time    string
+	Author  string
+	Summary string
+	Commit  string
+	Key     string
+	Error   string
+}
+
+type evidenceChecklist struct {
+	Label    string
+	Title    string
+	Reviewer string
+	LastEdit string
+	Version  string
+	State    string
+	Sections []bug.ChecklistSection
+}
+
+type evidenceReview struct {
+	RevisionId string
+	Title      string
+	Status     string
+	Updates    []evidenceReviewUpdate
+}
+
+type evidenceReviewUpdate struct {
+	TransId string
+	Time    string
+	Author  string
+	Kind    string
+	Detail  string
+}
+
+const evidenceTimeLayout = time.RFC3339
+
+func newEvidenceDocument(env *Env, b *cache.BugCache, validator *validate.Validator) *evidenceDocument {
+	snap := b.Snapshot()
+	workflow, labels := workflowAndLabels(snap)
+
+	doc := &evidenceDocument{
+		Id:          snap.Id().String(),
+		Title:       snap.Title,
+		Status:      snap.Status.String(),
+		Workflow:    workflow,
+		Author:      snap.Author.DisplayName(),
+		Assignee:    "UNASSIGNED",
+		Labels:      labels,
+		CreatedAt:   snap.CreateTime.Format(evidenceTimeLayout),
+		GeneratedAt: time.Now().Format(evidenceTimeLayout),
+	}
+	if snap.Assignee != nil {
+		doc.Assignee = snap.Assignee.DisplayName()
+	}
+	if validator.FirstKey != nil {
+		doc.FirstKey = validator.FirstKey.Fingerprint()
+	}
+
+	// Operations stored in the same commit share its signature, only check it once
+	commits := b.OperationCommits()
+	keys := make(map[string]string)
+	keyErrors := make(map[string]string)
+
+	for _, op := range snap.Operations {
+		eop := evidenceOperation{
+			Id:      op.Id().Human(),
+			Time:    op.Time().Format(evidenceTimeLayout),
+			Author:  op.GetAuthor().DisplayName(),
+			Summary: evidenceOperationSummary(op),
+		}
+
+		hash, ok := commits[op.Id()]
+		if !ok {
+			eop.Error = "not committed"
+			doc.Unverified++
+			doc.Operations = append(doc.Operations, eop)
+			continue
+		}
+		eop.Commit = hash.String()
+
+		if _, checked := keys[eop.Commit]; !checked {
+			if _, failed := keyErrors[eop.Commit]; !failed {
+				key, err := validator.ValidateCommit(hash)
+				if err != nil {
+					keyErrors[eop.Commit] = err.Error()
+				} else {
+					keys[eop.Commit] = strings.ToUpper(identity.EncodeKeyFingerprint(key.Fingerprint))
+				}
+			}
+		}
+
+		if msg, failed := keyErrors[eop.Commit]; failed {
+			eop.Error = msg
+			doc.Unverified++
+		} else {
+			eop.Key = keys[eop.Commit]
+		}
+
+		doc.Operations = append(doc.Operations, eop)
+	}
+
+	doc.Checklists = evidenceChecklists(env, snap)
+	doc.Reviews = evidenceReviews(snap)
+
+	return doc
+}
+
+// evidenceChecklists returns the checklist of each reviewer, rendered against
+// the template version it was answered under, sorted by label then reviewer
+func evidenceChecklists(env *Env, snap *bug.Snapshot) []evidenceChecklist {
+	var result []evidenceChecklist
+
+	checklistLabels := make([]bug.Label, 0, len(snap.Checklists))
+	for l := range snap.Checklists {
+		checklistLabels = append(checklistLabels, l)
+	}
+	sort.Slice(checklistLabels, func(i, j int) bool { return checklistLabels[i] < checklistLabels[j] })
+
+	for _, l := range checklistLabels {
+		reviewers := make([]entity.Id, 0, len(snap.Checklists[l]))
+		for reviewer := range snap.Checklists[l] {
+			reviewers = append(reviewers, reviewer)
+		}
+		sort.Slice(reviewers, func(i, j int) bool { return reviewers[i] < reviewers[j] })
+
+		for _, reviewer := range reviewers {
+			cl := snap.Checklists[l][reviewer]
+			pinned, version := pinnedChecklist(env, cl.Checklist)
+
+			result = append(result, evidenceChecklist{
+				Label:    l.String(),
+				Title:    pinned.Title,
+				Reviewer: reviewerName(env, reviewer),
+				LastEdit: cl.LastEdit.Format(evidenceTimeLayout),
+				Version:  version,
+				State:    pinned.CompoundState().String(),
+				Sections: pinned.Sections,
+			})
+		}
+	}
+
+	return result
+}
+
+// evidenceReviews returns all the transactions of the reviews of the ticket,
+// sorted by revision
+func evidenceReviews(snap *bug.Snapshot) []evidenceReview {
+	revisions := make([]string, 0, len(snap.Reviews))
+	for id := range snap.Reviews {
+		revisions = append(revisions, id)
+	}
+	sort.Strings(revisions)
+
+	result := make([]evidenceReview, 0, len(revisions))
+	for _, id := range revisions {
+		r := snap.Reviews[id]
+
+		review := evidenceReview{
+			RevisionId: r.RevisionId,
+			Title:      r.Title,
+			Status:     r.LatestOverallStatus(),
+		}
+
+		for _, u := range r.Updates {
+			update := evidenceReviewUpdate{
+				TransId: u.TransId,
+				Time:    time.Unix(u.Timestamp, 0).Format(evidenceTimeLayout),
+				Author:  u.PhabUser,
+			}
+			if u.Author != nil {
+				update.Author = u.Author.DisplayName()
+			}
+
+			switch u.Type {
+			case bug.CommentTransaction:
+				update.Kind = "comment"
+				update.Detail = u.Text
+				if u.Path != "" {
+					update.Detail = fmt.Sprintf("[%s:%d@%d] %s", u.Path, u.Line, u.Diff, u.Text)
+				}
+			case bug.StatusTransaction:
+				update.Kind = "status"
+				update.Detail = u.Status
+			case bug.UserStatusTransaction:
+				update.Kind = "user status"
+				update.Detail = u.Status
+			case bug.DiffTransaction:
+				update.Kind = "diff"
+				update.Detail = strconv.Itoa(u.DiffId)
+			}
+
+			review.Updates = append(review.Updates, update)
+		}
+
+		result = append(result, review)
+	}
+
+	return result
+}
+
+// evidenceOperationSummary describes what an operation did
+func evidenceOperationSummary(op bug.Operation) string {
+	switch op := op.(type) {
+	case *bug.CreateOperation:
+		return fmt.Sprintf("created the ticket %q", op.Title)
+	case *bug.SetTitleOperation:
+		return fmt.Sprintf("changed the title to %q", op.Title)
+	case *bug.AddCommentOperation:
+		return "added a comment"
+	case *bug.EditCommentOperation:
+		return fmt.Sprintf("edited the comment %s", op.Target.Human())
+	case *bug.SetStatusOperation:
+		return fmt.Sprintf("set the status to %s", op.Status)
+	case *bug.LabelChangeOperation:
+		var changes []string
+		for _, l := range op.Added {
+			changes = append(changes, "+"+l.String())
+		}
+		for _, l := range op.Removed {
+			changes = append(changes, "-"+l.String())
+		}
+		return fmt.Sprintf("changed the labels %s", strings.Join(changes, " "))
+	case *bug.SetAssigneeOperation:
+		if op.Assignee == nil {
+			return "unassigned the ticket"
+		}
+		return fmt.Sprintf("assigned the ticket to %s", op.Assignee.DisplayName())
+	case *bug.SetChecklistOperation:
+		return fmt.Sprintf("set %s to %s", op.Checklist.Label, op.Checklist.CompoundState())
+	case *bug.SetReviewOperation:
+		if op.Review.LastTransaction == bug.RemoveReviewInfo {
+			return fmt.Sprintf("removed the review %s", op.Review.RevisionId)
+		}
+		return fmt.Sprintf("updated the review %s with %d transaction(s)", op.Review.RevisionId, len(op.Review.Updates))
+	case *bug.SetMetadataOperation:
+		return fmt.Sprintf("set metadata on %s", op.Target.Human())
+	case *bug.NoOpOperation:
+		return "no-op"
+	default:
+		return fmt.Sprintf("%T", op)
+	}
+}
+
+// markdownCell escapes a value to fit in a cell of a markdown table
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+var evidenceMarkdownTemplate = template.Must(template.New("evidence").
+	Funcs(template.FuncMap{"cell": markdownCell}).
+	Parse(`# Evidence for ticket {{.Id}}
+
+| | |
+| --- | --- |
+| Title | {{cell .Title}} |
+| Status | {{.Status}} |
+| Workflow | {{cell .Workflow}} |
+| Author | {{cell .Author}} |
+| Assignee | {{cell .Assignee}} |
+| Labels | {{range $i, $l := .Labels}}{{if $i}}, {{end}}{{cell $l}}{{end}} |
+| Created | {{.CreatedAt}} |
+| Generated | {{.GeneratedAt}} |
+{{- if .FirstKey}}
+| First commit key | {{.FirstKey}} |
+{{- end}}
+
+## History
+
+| Time | Author | Operation | Commit | Signing key |
+| --- | --- | --- | --- | --- |
+{{- range .Operations}}
+| {{.Time}} | {{cell .Author}} | {{cell .Summary}} | {{.Commit}} | {{if .Error}}**NOT VERIFIED**: {{cell .Error}}{{else}}{{.Key}}{{end}} |
+{{- end}}
+{{if .Unverified}}
+**{{.Unverified}} operation(s) failed signature validation.**
+{{end}}
+## Checklists
+{{range .Checklists}}
+### {{.Title}} ({{.Label}}): {{.State}}
+
+Reviewed by {{.Reviewer}} on {{.LastEdit}}, {{.Version}}.
+{{range .Sections}}
+#### {{.Title}}
+
+| Question | State | Answer | Comment |
+| --- | --- | --- | --- |
+{{- range .Questions}}
+| {{cell .Question}} | {{.State}} | {{cell .Value}} | {{cell .Comment}} |
+{{- end}}
+{{end}}
+{{- else}}
+No checklist was answered.
+{{end}}
+## Reviews
+{{range .Reviews}}
+### {{.RevisionId}}: {{.Title}} ({{.Status}})
+
+| Transaction | Time | Author | Type | Detail |
+| --- | --- | --- | --- | --- |
+{{- range .Updates}}
+| {{.TransId}} | {{.Time}} | {{cell .Author}} | {{.Kind}} | {{cell .Detail}} |
+{{- end}}
+{{else}}
+No review is attached.
+{{end}}`))
+
+var evidenceHtmlTemplate = htmltemplate.Must(htmltemplate.New("evidence").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Evidence for ticket {{.Id}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #bbb; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+td.text { white-space: pre-wrap; }
+.hash { font-family: monospace; }
+.fail { color: #b00; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Evidence for ticket {{.Id}}</h1>
+<table>
+<tr><th>Title</th><td>{{.Title}}</td></tr>
+<tr><th>Status</th><td>{{.Status}}</td></tr>
+<tr><th>Workflow</th><td>{{.Workflow}}</td></tr>
+<tr><th>Author</th><td>{{.Author}}</td></tr>
+<tr><th>Assignee</th><td>{{.Assignee}}</td></tr>
+<tr><th>Labels</th><td>{{range $i, $l := .Labels}}{{if $i}}, {{end}}{{$l}}{{end}}</td></tr>
+<tr><th>Created</th><td>{{.CreatedAt}}</td></tr>
+<tr><th>Generated</th><td>{{.GeneratedAt}}</td></tr>
+{{- if .FirstKey}}
+<tr><th>First commit key</th><td class="hash">{{.FirstKey}}</td></tr>
+{{- end}}
+</table>
+
+<h2>History</h2>
+<table>
+<tr><th>Time</th><th>Author</th><th>Operation</th><th>Commit</th><th>Signing key</th></tr>
+{{- range .Operations}}
+<tr><td>{{.Time}}</td><td>{{.Author}}</td><td>{{.Summary}}</td><td class="hash">{{.Commit}}</td>
+{{- if .Error}}<td class="fail">NOT VERIFIED: {{.Error}}</td>{{else}}<td class="hash">{{.Key}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- if .Unverified}}
+<p class="fail">{{.Unverified}} operation(s) failed signature validation.</p>
+{{- end}}
+
+<h2>Checklists</h2>
+{{- range .Checklists}}
+<h3>{{.Title}} ({{.Label}}): {{.State}}</h3>
+<p>Reviewed by {{.Reviewer}} on {{.LastEdit}}, {{.Version}}.</p>
+{{- range .Sections}}
+<h4>{{.Title}}</h4>
+<table>
+<tr><th>Question</th><th>State</th><th>Answer</th><th>Comment</th></tr>
+{{- range .Questions}}
+<tr><td>{{.Question}}</td><td>{{.State}}</td><td>{{.Value}}</td><td class="text">{{.Comment}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- else}}
+<p>No checklist was answered.</p>
+{{- end}}
+
+<h2>Reviews</h2>
+{{- range .Reviews}}
+<h3>{{.RevisionId}}: {{.Title}} ({{.Status}})</h3>
+<table>
+<tr><th>Transaction</th><th>Time</th><th>Author</th><th>Type</th><th>Detail</th></tr>
+{{- range .Updates}}
+<tr><td class="hash">{{.TransId}}</td><td>{{.Time}}</td><td>{{.Author}}</td><td>{{.Kind}}</td><td class="text">{{.Detail}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No review is attached.</p>
+{{- end}}
+</body>
+</html>
+`))
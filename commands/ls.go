@@ -20,6 +20,10 @@ type lsOptions struct {
 	query query.Query
 
 	statusQuery   []string
+	createdAfter  string
+	createdBefore string
+	editedAfter   string
+	editedBefore  string
 	noQuery       []string
 	sortBy        string
 	sortDirection string
@@ -35,12 +39,29 @@ func newLsCommand() *cobra.Command {
 		Short: "List tickets.",
 		Long: `Display a summary of each ticket.
 
-You can pass an additional query to filter and order the list. This query can be expressed either with a simple query language or with flags.`,
+You can pass an additional query to filter and order the list. This query can be expressed either with a simple query language or with flags.
+
+The query can reference the views saved with "git ticket view save" as @name.`,
 		Example: `List vetted tickets sorted by last edition with a query:
 git ticket ls status:vetted sort:edit-desc
 
 List merged tickets sorted by creation with flags:
 git ticket ls --status merged --by creation
+
+List the tickets mentioning a watchdog timeout in their title or comments:
+git ticket ls "watchdog timeout"
+
+List the tickets in review with changes requested:
+git ticket ls review:needs-revision
+
+List the tickets edited in the last week:
+git ticket ls edited:7d
+
+List the tickets not edited for 30 days:
+git ticket ls --edited-before 30d
+
+List the tickets of the saved view triage with the label fpga:
+git ticket ls @triage label:fpga
 `,
 		PreRunE:  loadBackend(env),
 		PostRunE: closeBackend(env),
@@ -53,7 +74,7 @@ git ticket ls --status merged --by creation
 	flags.SortFlags = false
 
 	flags.StringSliceVarP(&options.statusQuery, "status", "s", nil,
-		"Filter by status. Valid values are [open,closed]")
+		"Filter by status. Valid values are the workflow statuses, e.g. [proposed,inprogress,merged]")
 	flags.StringSliceVarP(&options.query.Author, "author", "a", nil,
 		"Filter by author")
 	flags.StringSliceVarP(&options.query.Participant, "participant", "p", nil,
@@ -66,6 +87,14 @@ git ticket ls --status merged --by creation
 		"Filter by label")
 	flags.StringSliceVarP(&options.query.Title, "title", "t", nil,
 		"Filter by title")
+	flags.StringVarP(&options.createdAfter, "created-after", "", "",
+		"Filter by creation after a date (2026-01-01) or within a duration (7d)")
+	flags.StringVarP(&options.createdBefore, "created-before", "", "",
+		"Filter by creation before a date (2026-01-01) or a duration ago (30d)")
+	flags.StringVarP(&options.editedAfter, "edited-after", "", "",
+		"Filter by last edition after a date (2026-01-01) or within a duration (7d)")
+	flags.StringVarP(&options.editedBefore, "edited-before", "", "",
+		"Filter by last edition before a date (2026-01-01) or a duration ago (30d)")
 	flags.StringSliceVarP(&options.noQuery, "no", "n", nil,
 		"Filter by absence of something. Valid values are [label]")
 	flags.StringVarP(&options.sortBy, "by", "b", "creation",
@@ -83,13 +112,13 @@ func runLs(env *Env, opts lsOptions, args []string) error {
 	var err error
 
 	if len(args) >= 1 {
-		q, err = query.Parse(strings.Join(args, " "))
+		q, err = env.backend.ParseQuery(strings.Join(args, " "))
 
 		if err != nil {
 			return err
 		}
 	} else {
-		err = completeQuery(&opts)
+		err = completeQuery(env, &opts)
 		if err != nil {
 			return err
 		}
@@ -335,15 +364,36 @@ func lsOrgmodeFormatter(env *Env, bugExcerpts []*cache.BugExcerpt) error {
 }
 
 // Finish the command flags transformation into the query.Query
-func completeQuery(opts *lsOptions) error {
+func completeQuery(env *Env, opts *lsOptions) error {
 	for _, str := range opts.statusQuery {
-		status, err := bug.StatusFromString(str)
+		status, err := env.backend.Configs().StatusFromString(str)
 		if err != nil {
 			return err
 		}
 		opts.query.Status = append(opts.query.Status, status)
 	}
 
+	timeFlags := []struct {
+		value  string
+		parse  func(string) (query.TimeRange, error)
+		ranges *[]query.TimeRange
+	}{
+		{opts.createdAfter, query.ParseTimeAfter, &opts.query.Created},
+		{opts.createdBefore, query.ParseTimeBefore, &opts.query.Created},
+		{opts.editedAfter, query.ParseTimeAfter, &opts.query.Edited},
+		{opts.editedBefore, query.ParseTimeBefore, &opts.query.Edited},
+	}
+	for _, f := range timeFlags {
+		if f.value == "" {
+			continue
+		}
+		r, err := f.parse(f.value)
+		if err != nil {
+			return err
+		}
+		*f.ranges = append(*f.ranges, r)
+	}
+
 	for _, no := range opts.noQuery {
 		switch no {
 		case "label":
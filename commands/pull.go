@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/daedaleanai/git-ticket/bug"
 	"github.com/daedaleanai/git-ticket/entity"
 )
 
@@ -43,6 +44,15 @@ func runPull(env *Env, args []string) error {
 
 	env.out.Println(stdout)
 
+	// The configs are updated first, the merged tickets are checked against the
+	// workflows and groups of the remote
+	env.out.Println("Updating configs ...")
+	stdout, err = env.backend.UpdateConfigs(remote)
+	env.out.Print(stdout)
+	if err != nil {
+		return err
+	}
+
 	env.out.Println("Merging data ...")
 
 	for result := range env.backend.MergeAll(remote) {
@@ -53,13 +63,17 @@ func runPull(env *Env, args []string) error {
 		if result.Status != entity.MergeStatusNothing {
 			env.out.Printf("%s: %s\n", result.Id.Human(), result)
 		}
-	}
 
-	env.out.Println("Updating configs ...")
-	stdout, err = env.backend.UpdateConfigs(remote)
-	env.out.Print(stdout)
-	if err != nil {
-		return err
+		// Flag the merged status changes the workflows don't allow
+		if b, ok := result.Entity.(*bug.Bug); ok && result.Err == nil {
+			switch result.Status {
+			case entity.MergeStatusNew, entity.MergeStatusUpdated:
+				for _, u := range bug.CheckMergedTransitionPermissions(env.backend.Configs(), b) {
+					env.err.Printf("Warning: %s: status change not allowed on %s: %s\n",
+						result.Id.Human(), u.Op.Time().Format("2006-01-02 15:04:05"), u.Err)
+				}
+			}
+		}
 	}
 
 	return nil
@@ -12,7 +12,10 @@ func newReviewCommand() *cobra.Command {
 
 	cmd.AddCommand(newReviewChecklistCommand())
 	cmd.AddCommand(newReviewClearCommand())
+	cmd.AddCommand(newReviewDiscoverCommand())
 	cmd.AddCommand(newReviewFetchCommand())
+	cmd.AddCommand(newReviewShowCommand())
+	cmd.AddCommand(newReviewSyncCommand())
 
 	return cmd
 }
@@ -7,12 +7,19 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/cache"
 	_select "github.com/daedaleanai/git-ticket/commands/select"
+	"github.com/daedaleanai/git-ticket/entity"
 	"github.com/daedaleanai/git-ticket/input"
 )
 
+type reviewChecklistOptions struct {
+	upgrade bool
+}
+
 func newReviewChecklistCommand() *cobra.Command {
 	env := newEnv()
+	options := reviewChecklistOptions{}
 
 	cmd := &cobra.Command{
 		Use:      "checklist [ID]",
@@ -20,15 +27,21 @@ func newReviewChecklistCommand() *cobra.Command {
 		PreRunE:  loadBackendEnsureUser(env),
 		PostRunE: closeBackend(env),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runReviewChecklist(env, args)
+			return runReviewChecklist(env, options, args)
 		},
 	}
 
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.BoolVarP(&options.upgrade, "upgrade", "u", false,
+		"Move your answers to the latest version of the checklist templates, reporting the questions added and removed")
+
 	return cmd
 
 }
 
-func runReviewChecklist(env *Env, args []string) error {
+func runReviewChecklist(env *Env, opts reviewChecklistOptions, args []string) error {
 	b, args, err := _select.ResolveBug(env.backend, args)
 	if err != nil {
 		return err
@@ -39,7 +52,11 @@ func runReviewChecklist(env *Env, args []string) error {
 		return err
 	}
 
-	ticketChecklists, err := b.Snapshot().GetUserChecklists(id.Id())
+	if opts.upgrade {
+		return upgradeChecklists(env, b, id.Id())
+	}
+
+	ticketChecklists, err := b.Snapshot().GetUserChecklists(env.backend.Configs(), id.Id())
 	if err != nil {
 		return err
 	}
@@ -95,3 +112,59 @@ func runReviewChecklist(env *Env, args []string) error {
 	fmt.Println("Checklists unchanged")
 	return nil
 }
+
+// upgradeChecklists moves the answers of the reviewer to the latest version of
+// the checklist templates
+func upgradeChecklists(env *Env, b *cache.BugCache, reviewer entity.Id) error {
+	snap := b.Snapshot()
+	upgraded := 0
+
+	for _, l := range snap.Labels {
+		if !l.IsChecklist() {
+			continue
+		}
+
+		answered, present := snap.Checklists[l][reviewer]
+		if !present {
+			continue
+		}
+
+		template, err := env.backend.Configs().Checklist(l)
+		if err != nil {
+			return err
+		}
+
+		cl, upgrade := bug.UpgradeChecklist(answered.Checklist, template)
+		if upgrade.IsNoop() {
+			env.out.Printf("%s: up to date\n", l)
+			continue
+		}
+
+		from := "unversioned"
+		if upgrade.From != "" {
+			from = shortHash(upgrade.From)
+		}
+		env.out.Printf("%s: template %s -> %s\n", l, from, shortHash(upgrade.To))
+		for _, q := range upgrade.Added {
+			env.out.Printf("  added: %s\n", q)
+		}
+		for _, q := range upgrade.Removed {
+			env.out.Printf("  removed: %s\n", q)
+		}
+		for _, q := range upgrade.Changed {
+			env.out.Printf("  needs a new answer: %s\n", q)
+		}
+
+		if _, err := b.SetChecklist(cl); err != nil {
+			return err
+		}
+		upgraded++
+	}
+
+	if upgraded == 0 {
+		env.out.Println("No checklists to upgrade")
+		return nil
+	}
+
+	return b.Commit()
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/cache"
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+type reviewDiscoverOptions struct {
+	since  time.Duration
+	dryRun bool
+}
+
+func newReviewDiscoverCommand() *cobra.Command {
+	env := newEnv()
+	options := reviewDiscoverOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "discover",
+		Short: "Attach the recent Phabricator reviews referencing tickets to them.",
+		Long: `discover queries Phabricator for the Differential Revisions modified recently, and
+stores the ones referencing tickets with them, as "git ticket review fetch" does.
+
+A revision references a ticket when its title or summary contains the id of the ticket,
+or a prefix of it at least 7 characters long. The ids can also be given in a trailer
+line of the summary, e.g. "Ticket: abc1234", the name of the trailer being set with the
+git config daedalean.review-ticket-trailer. Prefixes matching several tickets are
+reported and skipped.
+
+Revisions already stored in a ticket are left to "git ticket review sync".`,
+		PreRunE:  loadBackendEnsureUser(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReviewDiscover(env, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.DurationVarP(&options.since, "since", "s", 7*24*time.Hour,
+		"Only query the revisions modified within this duration")
+	flags.BoolVarP(&options.dryRun, "dry-run", "n", false,
+		"List the revisions which would be attached without storing them")
+
+	return cmd
+}
+
+func runReviewDiscover(env *Env, opts reviewDiscoverOptions) error {
+	provider := env.backend.PhabricatorProvider()
+
+	revisions, err := provider.SearchRevisions(time.Now().Add(-opts.since))
+	if err != nil {
+		return err
+	}
+
+	trailer := bug.ReviewTicketTrailer(env.repo)
+
+	var attached, existing, ambiguous, failed int
+
+	for _, revision := range revisions {
+		tickets, err := discoverReviewTickets(env, trailer, revision, &ambiguous)
+		if err != nil {
+			return err
+		}
+
+		var review *bug.ReviewInfo
+
+		for _, b := range tickets {
+			if _, ok := b.Snapshot().Reviews[revision.RevisionId]; ok {
+				existing++
+				continue
+			}
+
+			if opts.dryRun {
+				env.out.Printf("%s:%s would be attached to %s %s\n",
+					revision.RevisionId, revision.Title, colors.Cyan(b.Id().Human()), b.Snapshot().Title)
+				attached++
+				continue
+			}
+
+			if review == nil {
+				review, err = provider.FetchReviewInfo(revision.RevisionId, "")
+				if err != nil {
+					failed++
+					env.err.Printf("%s: %s\n", revision.RevisionId, err)
+					break
+				}
+			}
+
+			if _, err := b.SetReview(review); err != nil {
+				failed++
+				env.err.Printf("%s %s: %s\n", colors.Cyan(b.Id().Human()), revision.RevisionId, err)
+				continue
+			}
+			if err := b.Commit(); err != nil {
+				return err
+			}
+
+			env.out.Printf("%s:%s attached to %s %s\n",
+				revision.RevisionId, revision.Title, colors.Cyan(b.Id().Human()), b.Snapshot().Title)
+			attached++
+		}
+	}
+
+	verb := "attached"
+	if opts.dryRun {
+		verb = "to attach"
+	}
+	env.out.Printf("%d revision(s) scanned, %d %s, %d already attached, %d ambiguous reference(s), %d failed\n",
+		len(revisions), attached, verb, existing, ambiguous, failed)
+
+	if failed > 0 {
+		return errors.Errorf("%d revision(s) failed to be attached", failed)
+	}
+
+	return nil
+}
+
+// discoverReviewTickets returns the tickets referenced by the title or summary
+// of a revision. The ambiguous references are reported and counted.
+func discoverReviewTickets(env *Env, trailer string, revision bug.RevisionSummary, ambiguous *int) ([]*cache.BugCache, error) {
+	explicit, mentioned := bug.ReviewTicketRefs(trailer, revision.Title, revision.Summary)
+
+	found := make(map[entity.Id]*cache.BugCache)
+
+	resolve := func(prefix string, isExplicit bool) error {
+		b, err := env.backend.ResolveBugPrefix(prefix)
+		switch {
+		case err == nil:
+			found[b.Id()] = b
+		case entity.IsErrMultipleMatch(err):
+			*ambiguous++
+			env.err.Printf("%s: ticket reference %s is ambiguous, skipped: %s\n", revision.RevisionId, prefix, err)
+		case err == bug.ErrBugNotExist:
+			// Anything looking like an id may be mentioned, only the trailers
+			// must reference tickets
+			if isExplicit {
+				env.err.Printf("%s: no ticket matching %s\n", revision.RevisionId, prefix)
+			}
+		default:
+			return err
+		}
+		return nil
+	}
+
+	for _, prefix := range explicit {
+		if err := resolve(prefix, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, prefix := range mentioned {
+		if err := resolve(prefix, false); err != nil {
+			return nil, err
+		}
+	}
+
+	tickets := make([]*cache.BugCache, 0, len(found))
+	for _, b := range found {
+		tickets = append(tickets, b)
+	}
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].Id() < tickets[j].Id() })
+
+	return tickets, nil
+}
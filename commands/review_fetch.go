@@ -6,7 +6,6 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/daedaleanai/git-ticket/bug"
 	_select "github.com/daedaleanai/git-ticket/commands/select"
 )
 
@@ -24,6 +23,12 @@ is stored with the selected ticket. Subsequent calls with the same ID will fetch
 store any updates since the previous call. Multiple Revisions can be stored with a
 ticket by running the command with different IDs.
 
+Gerrit changes are fetched the same way, with their number prefixed by G (e.g. G1234),
+from the server set in the git config daedalean.gerrit-url. The requests are
+authenticated with the daedalean.gerrit-user and daedalean.gerrit-token git configs if
+set. IDs given without prefix are fetched from the provider set in the git config
+daedalean.review-provider, "phabricator" or "gerrit", Phabricator by default.
+
 `,
 		PreRunE:  loadBackendEnsureUser(env),
 		PostRunE: closeBackend(env),
@@ -40,7 +45,15 @@ func runReviewFetch(env *Env, args []string) error {
 		return errors.New("no DiffID supplied")
 	}
 
-	diffId := args[0]
+	providers, err := env.backend.ReviewProviders()
+	if err != nil {
+		return err
+	}
+
+	provider, diffId, err := providers.Resolve(args[0])
+	if err != nil {
+		return err
+	}
 	args = args[1:]
 
 	b, args, err := _select.ResolveBug(env.backend, args)
@@ -50,15 +63,13 @@ func runReviewFetch(env *Env, args []string) error {
 
 	// If we already have review data for this Differential then just get any updates
 	// since then
-	var lastUpdate string
-	if existingReview, ok := b.Snapshot().Reviews[diffId]; ok {
-		lastUpdate = existingReview.LastTransaction
-	}
+	existingReview := b.Snapshot().Reviews[diffId]
 
-	review, err := bug.FetchReviewInfo(diffId, lastUpdate)
+	review, err := provider.FetchReviewInfo(diffId, existingReview.LastTransaction)
 	if err != nil {
 		return err
 	}
+	review.RemoveKnownUpdates(existingReview)
 
 	if len(review.Updates) == 0 {
 		fmt.Printf("No updates to save for %s, aborting\n", diffId)
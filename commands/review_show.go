@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	_select "github.com/daedaleanai/git-ticket/commands/select"
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+type reviewShowOptions struct {
+	context int
+}
+
+func newReviewShowCommand() *cobra.Command {
+	env := newEnv()
+	options := reviewShowOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "show [ID] [DIFF-ID]",
+		Short: "Show the inline comments of the reviews of a ticket.",
+		Long: `show prints the inline comment threads of the reviews stored in a ticket, or of the
+given review only, grouped by file and by resolved status.
+
+The lines of the file around each thread are shown when the revision the comments were
+made against is available in the local repository: the refs/tags/phabricator/diff/N tags
+pushed by arc to the staging area for Phabricator, the refs/changes/NN/CHANGE/PATCHSET
+refs for Gerrit.`,
+		PreRunE:  loadBackend(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReviewShow(env, options, args)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.IntVarP(&options.context, "context", "C", 3,
+		"Number of lines of source shown before and after the commented line")
+
+	return cmd
+}
+
+func runReviewShow(env *Env, opts reviewShowOptions, args []string) error {
+	if opts.context < 0 {
+		return fmt.Errorf("invalid number of context lines %d", opts.context)
+	}
+
+	b, args, err := _select.ResolveBug(env.backend, args)
+	if err != nil {
+		return err
+	}
+
+	providers, err := env.backend.ReviewProviders()
+	if err != nil {
+		return err
+	}
+
+	snap := b.Snapshot()
+
+	var reviews []bug.ReviewInfo
+	if len(args) > 0 {
+		_, diffId, err := providers.Resolve(args[0])
+		if err != nil {
+			return err
+		}
+		review, ok := snap.Reviews[diffId]
+		if !ok {
+			return fmt.Errorf("no review %s stored in ticket %s", diffId, b.Id().Human())
+		}
+		reviews = append(reviews, review)
+	} else {
+		for _, review := range snap.Reviews {
+			reviews = append(reviews, review)
+		}
+		sort.Slice(reviews, func(i, j int) bool { return reviews[i].RevisionId < reviews[j].RevisionId })
+	}
+
+	if len(reviews) == 0 {
+		env.out.Println("No reviews stored in the ticket")
+		return nil
+	}
+
+	sources := newReviewSources(env, providers)
+
+	for _, review := range reviews {
+		env.out.Printf("==== %s:%s (%s) ====\n", review.RevisionId, review.Title, review.LatestOverallStatus())
+
+		threads := review.InlineThreads()
+		if len(threads) == 0 {
+			env.out.Printf("No inline comments\n\n")
+			continue
+		}
+
+		// Group the threads of each file by status, the unresolved ones first
+		for start := 0; start < len(threads); {
+			end := start
+			for end < len(threads) && threads[end].Path == threads[start].Path {
+				end++
+			}
+
+			for _, resolved := range []bool{false, true} {
+				var group []bug.ReviewThread
+				for _, t := range threads[start:end] {
+					if t.Resolved() == resolved {
+						group = append(group, t)
+					}
+				}
+				if len(group) == 0 {
+					continue
+				}
+
+				status := colors.Red("unresolved")
+				if resolved {
+					status = colors.Green("resolved")
+				}
+				env.out.Printf("---- %s (%s) ----\n", threads[start].Path, status)
+
+				for _, t := range group {
+					showReviewThread(env, sources, review.RevisionId, t, opts.context)
+				}
+			}
+
+			start = end
+		}
+	}
+
+	return nil
+}
+
+func showReviewThread(env *Env, sources *reviewSources, revisionId string, thread bug.ReviewThread, context int) {
+	env.out.Printf("%s:%d @%d\n", thread.Path, thread.Line, thread.Diff)
+
+	lines, ref := sources.lines(revisionId, thread.Diff, thread.Path)
+	switch {
+	case thread.Line <= 0:
+		// Comment on the whole file
+	case lines == nil:
+		env.out.Printf("  (source not available locally at %s)\n", ref)
+	default:
+		first := thread.Line - context
+		if first < 1 {
+			first = 1
+		}
+		last := thread.Line + context
+		if last > len(lines) {
+			last = len(lines)
+		}
+		for n := first; n <= last; n++ {
+			marker := " "
+			if n == thread.Line {
+				marker = ">"
+			}
+			env.out.Printf("%s %5d | %s\n", marker, n, lines[n-1])
+		}
+	}
+
+	for _, c := range thread.Comments {
+		text := strings.ReplaceAll(strings.TrimSpace(c.Text), "\n", "\n    ")
+		env.out.Printf("  (%s) %s: %s\n", time.Unix(c.Timestamp, 0).Format(time.RFC822), reviewUserName(env, c), text)
+	}
+	env.out.Println()
+}
+
+// reviewUserName returns the name of the author of a review update. The
+// identity currently matching their review system user is preferred to the one
+// stored with the update, so that the updates stored before the user was
+// matched to an identity are shown with it.
+func reviewUserName(env *Env, u bug.ReviewUpdate) string {
+	if i, err := env.backend.ResolveIdentityReviewer(u.PhabUser); err == nil {
+		return i.DisplayName()
+	}
+	if u.Author != nil {
+		return u.Author.DisplayName()
+	}
+	return u.PhabUser
+}
+
+// reviewSources reads the files reviewed from the local repository, at the
+// revision of the diff they were reviewed in
+type reviewSources struct {
+	env       *Env
+	providers *bug.ReviewProviders
+	files     map[string][]string
+}
+
+func newReviewSources(env *Env, providers *bug.ReviewProviders) *reviewSources {
+	return &reviewSources{
+		env:       env,
+		providers: providers,
+		files:     make(map[string][]string),
+	}
+}
+
+// lines returns the lines of the file at the given diff of the review, or nil
+// if the revision or the file isn't available locally, along with the ref of
+// the revision
+func (s *reviewSources) lines(revisionId string, diff int, path string) ([]string, string) {
+	ref, err := s.providers.RevisionRef(revisionId, diff)
+	if err != nil {
+		return nil, ""
+	}
+
+	key := ref + ":" + path
+	if lines, ok := s.files[key]; ok {
+		return lines, ref
+	}
+	s.files[key] = nil
+
+	hash, err := s.env.backend.ResolveRef(ref)
+	if err != nil {
+		return nil, ref
+	}
+	commit, err := s.env.backend.ResolveCommit(hash)
+	if err != nil {
+		return nil, ref
+	}
+	file, err := commit.File(path)
+	if err != nil {
+		return nil, ref
+	}
+	lines, err := file.Lines()
+	if err != nil {
+		return nil, ref
+	}
+
+	s.files[key] = lines
+	return lines, ref
+}
@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+type reviewSyncOptions struct {
+	skipClosed bool
+	jobs       int
+}
+
+func newReviewSyncCommand() *cobra.Command {
+	env := newEnv()
+	options := reviewSyncOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Get the updates of every review stored in the tickets.",
+		Long: `sync fetches the updates of all the Phabricator Differential Revisions and Gerrit
+changes stored in the tickets, as "git ticket review fetch" does for a single one, and
+stores them with their ticket.
+
+The revisions are queried concurrently, starting from the last transaction stored. The
+tickets which changed are listed once done.`,
+		PreRunE:  loadBackendEnsureUser(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReviewSync(env, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.BoolVarP(&options.skipClosed, "skip-closed", "s", false,
+		"Don't query the revisions which are published or abandoned")
+	flags.IntVarP(&options.jobs, "jobs", "j", 4,
+		"Number of revisions queried concurrently")
+
+	return cmd
+}
+
+// reviewSyncJob is a revision of a ticket to fetch the updates of
+type reviewSyncJob struct {
+	ticket   entity.Id
+	revision string
+	since    string
+	title    string
+}
+
+type reviewSyncResult struct {
+	reviewSyncJob
+	review *bug.ReviewInfo
+	err    error
+}
+
+func runReviewSync(env *Env, opts reviewSyncOptions) error {
+	if opts.jobs < 1 {
+		return fmt.Errorf("invalid number of jobs %d", opts.jobs)
+	}
+
+	var jobs []reviewSyncJob
+	var skipped int
+
+	for _, id := range env.backend.AllBugsIds() {
+		b, err := env.backend.ResolveBug(id)
+		if err != nil {
+			return err
+		}
+
+		for revision, review := range b.Snapshot().Reviews {
+			if opts.skipClosed && review.IsClosed() {
+				skipped++
+				continue
+			}
+			jobs = append(jobs, reviewSyncJob{
+				ticket:   id,
+				revision: revision,
+				since:    review.LastTransaction,
+				title:    review.Title,
+			})
+		}
+	}
+
+	providers, err := env.backend.ReviewProviders()
+	if err != nil {
+		return err
+	}
+
+	results := fetchReviews(providers, jobs, opts.jobs)
+
+	// Store the updates ticket by ticket, in a stable order
+	byTicket := make(map[entity.Id][]reviewSyncResult)
+	var tickets []entity.Id
+	for _, r := range results {
+		if _, ok := byTicket[r.ticket]; !ok {
+			tickets = append(tickets, r.ticket)
+		}
+		byTicket[r.ticket] = append(byTicket[r.ticket], r)
+	}
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i] < tickets[j] })
+
+	var updated, failed int
+
+	for _, id := range tickets {
+		b, err := env.backend.ResolveBug(id)
+		if err != nil {
+			return err
+		}
+
+		ticketResults := byTicket[id]
+		sort.Slice(ticketResults, func(i, j int) bool { return ticketResults[i].revision < ticketResults[j].revision })
+
+		var changes []string
+		for _, r := range ticketResults {
+			if r.err != nil {
+				failed++
+				env.err.Printf("%s %s: %s\n", colors.Cyan(id.Human()), r.revision, r.err)
+				continue
+			}
+			r.review.RemoveKnownUpdates(b.Snapshot().Reviews[r.revision])
+			if len(r.review.Updates) == 0 {
+				continue
+			}
+
+			// Only the changes are fetched, keep the title if it didn't change
+			if r.review.Title == "" {
+				r.review.Title = r.title
+			}
+
+			if _, err := b.SetReview(r.review); err != nil {
+				failed++
+				env.err.Printf("%s %s: %s\n", colors.Cyan(id.Human()), r.revision, err)
+				continue
+			}
+			changes = append(changes, fmt.Sprintf("%s (%d update(s))", r.revision, len(r.review.Updates)))
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		if err := b.Commit(); err != nil {
+			return err
+		}
+		updated++
+
+		env.out.Printf("%s %s: %s\n", colors.Cyan(id.Human()), b.Snapshot().Title, strings.Join(changes, ", "))
+	}
+
+	env.out.Printf("%d revision(s) queried, %d closed skipped, %d failed, %d ticket(s) updated\n",
+		len(jobs), skipped, failed, updated)
+
+	if failed > 0 {
+		return errors.Errorf("%d revision(s) failed to sync", failed)
+	}
+
+	return nil
+}
+
+// fetchReviews gets the updates of the revisions with at most workers queries
+// running at the same time
+func fetchReviews(providers *bug.ReviewProviders, jobs []reviewSyncJob, workers int) []reviewSyncResult {
+	jobChan := make(chan reviewSyncJob)
+	resultChan := make(chan reviewSyncResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobChan {
+				review, err := providers.FetchReviewInfo(job.revision, job.since)
+				resultChan <- reviewSyncResult{reviewSyncJob: job, review: review, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, job := range jobs {
+			jobChan <- job
+		}
+		close(jobChan)
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	results := make([]reviewSyncResult, 0, len(jobs))
+	for r := range resultChan {
+		results = append(results, r)
+	}
+
+	return results
+}
@@ -62,10 +62,12 @@ _git_bug() {
 
 	cmd.AddCommand(newAddCommand())
 	cmd.AddCommand(newAssignCommand())
+	cmd.AddCommand(newChecklistCommand())
 	cmd.AddCommand(newCommandsCommand())
 	cmd.AddCommand(newCommentCommand())
 	cmd.AddCommand(newConfigCommand())
 	cmd.AddCommand(newDeselectCommand())
+	cmd.AddCommand(newExportCommand())
 	cmd.AddCommand(newLabelCommand())
 	cmd.AddCommand(newLsCommand())
 	cmd.AddCommand(newLsIdCommand())
@@ -82,6 +84,8 @@ _git_bug() {
 	cmd.AddCommand(newUserCommand())
 	cmd.AddCommand(newValidateCommand())
 	cmd.AddCommand(newVersionCommand())
+	cmd.AddCommand(newViewCommand())
+	cmd.AddCommand(newWorkflowCommand())
 
 	return cmd
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/daedaleanai/git-ticket/bug"
 	_select "github.com/daedaleanai/git-ticket/commands/select"
+	"github.com/daedaleanai/git-ticket/repository"
 	"github.com/daedaleanai/git-ticket/util/colors"
 )
 
@@ -98,7 +99,8 @@ func runShow(env *Env, opts showOptions, args []string) error {
 					if err != nil {
 						return err
 					}
-					env.out.Printf("%s reviewed %s: %s\n", reviewer.DisplayName(), cl.LastEdit, cl)
+					pinned, version := pinnedChecklist(env, cl.Checklist)
+					env.out.Printf("%s reviewed %s (%s): %s\n", reviewer.DisplayName(), cl.LastEdit, version, pinned)
 				}
 			}
 		case "reviews":
@@ -108,7 +110,7 @@ func runShow(env *Env, opts showOptions, args []string) error {
 
 				// The statuses
 				for _, s := range r.LatestUserStatuses() {
-					env.out.Printf("(%s) %-20s: %s\n", time.Unix(s.Timestamp, 0).Format(time.RFC822), s.Author.DisplayName(), s.Status)
+					env.out.Printf("(%s) %-20s: %s\n", time.Unix(s.Timestamp, 0).Format(time.RFC822), reviewUserName(env, s), s.Status)
 				}
 
 				// Output all the comments
@@ -118,7 +120,7 @@ func runShow(env *Env, opts showOptions, args []string) error {
 					if c.Type != bug.CommentTransaction {
 						continue
 					}
-					env.out.Printf("(%s) %-20s: %s\n", time.Unix(c.Timestamp, 0).Format(time.RFC822), c.Author.DisplayName(), c.OneLineComment())
+					env.out.Printf("(%s) %-20s: %s\n", time.Unix(c.Timestamp, 0).Format(time.RFC822), reviewUserName(env, c), c.OneLineComment())
 				}
 			}
 		case "labels":
@@ -174,6 +176,52 @@ func workflowAndLabels(snap *bug.Snapshot) (string, []string) {
 	return workflow, labels
 }
 
+// checklistTitle returns the title of the checklist with the given label, as
+// given by the template it was answered under if anyone answered it
+func checklistTitle(env *Env, snapshot *bug.Snapshot, label bug.Label) (string, error) {
+	for _, cl := range snapshot.Checklists[label] {
+		pinned, _ := pinnedChecklist(env, cl.Checklist)
+		return pinned.Title, nil
+	}
+
+	cl, err := env.backend.Configs().Checklist(label)
+	if err != nil {
+		return "", err
+	}
+	return cl.Title, nil
+}
+
+// pinnedChecklist returns the answers of the checklist applied to the template
+// version they were given under, along with a description of that version.
+// Checklists answered before templates were versioned, or whose template can't
+// be found, are returned as they were stored.
+func pinnedChecklist(env *Env, cl bug.Checklist) (bug.Checklist, string) {
+	if cl.Version == "" {
+		return cl, "unversioned template"
+	}
+
+	template, err := env.backend.Configs().ChecklistAt(cl.Label, cl.Version)
+	if err != nil {
+		return cl, fmt.Sprintf("template unavailable: %s", err)
+	}
+
+	pinned, _ := bug.UpgradeChecklist(cl, template)
+
+	version := "template " + shortHash(cl.Version)
+	if current, err := env.backend.Configs().Checklist(cl.Label); err == nil && current.Version != cl.Version {
+		version += ", outdated"
+	}
+	return pinned, version
+}
+
+// shortHash returns the abbreviated form of a git hash
+func shortHash(h repository.Hash) string {
+	if len(h) > 7 {
+		return string(h[:7])
+	}
+	return string(h)
+}
+
 func showDefaultFormatter(env *Env, snapshot *bug.Snapshot) error {
 	assigneeName := "UNASSIGNED"
 	if snapshot.Assignee != nil {
@@ -204,13 +252,12 @@ func showDefaultFormatter(env *Env, snapshot *bug.Snapshot) error {
 	// Checklists
 	var checklistStates []string
 	for clLabel, st := range snapshot.GetChecklistCompoundStates() {
-		cl, err := bug.GetChecklist(clLabel)
-
+		title, err := checklistTitle(env, snapshot, clLabel)
 		if err != nil {
 			return err
 		}
 
-		checklistStates = append(checklistStates, fmt.Sprintf("%s (%s)", cl.Title, st.ColorString()))
+		checklistStates = append(checklistStates, fmt.Sprintf("%s (%s)", title, st.ColorString()))
 	}
 	env.out.Printf("checklists: %s\n", strings.Join(checklistStates, ", "))
 
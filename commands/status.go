@@ -19,6 +19,7 @@ func newStatusCommand() *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(newStatusSetCommand())
 	for setCmd := range newStatusSetCommands() {
 		cmd.AddCommand(setCmd)
 	}
@@ -7,12 +7,14 @@ import (
 	_select "github.com/daedaleanai/git-ticket/commands/select"
 )
 
+// newStatusSetCommands returns a subcommand per built-in status. The statuses
+// added by the workflows of a repository are set with "status set".
 func newStatusSetCommands() <-chan *cobra.Command {
 	env := newEnv()
 
 	cmds := make(chan *cobra.Command)
 	go func() {
-		for s := bug.FirstStatus; s <= bug.LastStatus; s++ {
+		for _, s := range bug.BuiltinStatuses() {
 			temp := s
 			cmd := &cobra.Command{
 				Use:      s.String() + " ID",
@@ -37,6 +39,29 @@ func newStatusSetCommands() <-chan *cobra.Command {
 	return cmds
 }
 
+func newStatusSetCommand() *cobra.Command {
+	env := newEnv()
+
+	cmd := &cobra.Command{
+		Use:   "set STATUS [ID]",
+		Short: "Set the status of a ticket.",
+		Long: `set changes the status of a ticket to any status known in the repository: the built-in
+ones and the states of the configured workflows.`,
+		Args:     cobra.RangeArgs(1, 2),
+		PreRunE:  loadBackendEnsureUser(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := env.backend.Configs().StatusFromString(args[0])
+			if err != nil {
+				return err
+			}
+			return runStatusSet(env, args[1:], s)
+		},
+	}
+
+	return cmd
+}
+
 func runStatusSet(env *Env, args []string, s bug.Status) error {
 	b, args, err := _select.ResolveBug(env.backend, args)
 	if err != nil {
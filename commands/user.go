@@ -44,6 +44,7 @@ func newUserCommand() *cobra.Command {
 	cmd.AddCommand(newUserEditCommand())
 	cmd.AddCommand(newUserKeyCommand())
 	cmd.AddCommand(newUserLsCommand())
+	cmd.AddCommand(newUserPhabSyncCommand())
 
 	flags := cmd.Flags()
 	flags.SortFlags = false
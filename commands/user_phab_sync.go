@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/cache"
+	"github.com/daedaleanai/git-ticket/entity"
+	"github.com/daedaleanai/git-ticket/identity"
+	"github.com/daedaleanai/git-ticket/input"
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+type userPhabSyncOptions struct {
+	yes bool
+}
+
+func newUserPhabSyncCommand() *cobra.Command {
+	env := newEnv()
+	options := userPhabSyncOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "phab-sync",
+		Short: "Match the Phabricator users of the stored reviews to identities.",
+		Long: `phab-sync lists the Phabricator users who took part in the reviews stored in the
+tickets, along with the identity they are shown as.
+
+The Phabricator user of every identity is then looked up from their email, the prefix of
+the email being their Phabricator username. The identities which aren't matched to
+the Phabricator users of the reviews yet are proposed to be, once done the reviews
+show the updates of these users as made by the identities.`,
+		PreRunE:  loadBackendEnsureUser(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUserPhabSync(env, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.BoolVarP(&options.yes, "yes", "y", false,
+		"Apply all the changes proposed without asking")
+
+	return cmd
+}
+
+// phabIdProposal is a Phabricator ID to set on an identity
+type phabIdProposal struct {
+	identity *cache.IdentityCache
+	phabID   string
+}
+
+func runUserPhabSync(env *Env, opts userPhabSyncOptions) error {
+	// The Phabricator users of the reviews and their number of updates
+	seen := make(map[string]int)
+	var reviews int
+
+	for _, id := range env.backend.AllBugsIds() {
+		b, err := env.backend.ResolveBug(id)
+		if err != nil {
+			return err
+		}
+		for _, review := range b.Snapshot().Reviews {
+			reviews++
+			for _, u := range review.Updates {
+				// The other review providers identify the users by email
+				if u.PhabUser == "" || strings.Contains(u.PhabUser, "@") {
+					continue
+				}
+				seen[u.PhabUser]++
+			}
+		}
+	}
+
+	if len(seen) == 0 {
+		env.out.Println("No Phabricator users in the stored reviews")
+		return nil
+	}
+
+	users := make([]string, 0, len(seen))
+	for user := range seen {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+
+	env.out.Printf("Phabricator users of %d review(s):\n", reviews)
+	for _, user := range users {
+		env.out.Printf("  %-30s %5d update(s)  %s\n", user, seen[user], phabUserIdentity(env, user))
+	}
+
+	proposals, err := proposePhabIds(env, seen)
+	if err != nil {
+		return err
+	}
+
+	if len(proposals) == 0 {
+		env.out.Println("No identity to update")
+		return nil
+	}
+
+	env.out.Printf("\nIdentities to update:\n")
+	for _, p := range proposals {
+		env.out.Printf("  %s %s <%s>: Phabricator ID %q -> %s\n",
+			colors.Cyan(p.identity.Id().Human()), p.identity.DisplayName(), p.identity.Email(), p.identity.PhabID(), p.phabID)
+	}
+	env.out.Println()
+
+	var applied, updates int
+	for _, p := range proposals {
+		if !opts.yes {
+			ok, err := input.PromptConfirm(fmt.Sprintf("Set the Phabricator ID of %s to %s?", p.identity.DisplayName(), p.phabID))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		if err := env.backend.SetIdentityPhabID(p.identity, p.phabID); err != nil {
+			return err
+		}
+		applied++
+		updates += seen[p.phabID]
+	}
+
+	env.out.Printf("%d identity(ies) updated, %d review update(s) now shown as their identity\n", applied, updates)
+
+	return nil
+}
+
+// proposePhabIds looks up the Phabricator ID of the identities and returns the
+// ones to set to match the given Phabricator users. A user matching several
+// identities, or an identity whose lookup fails, is reported and skipped.
+func proposePhabIds(env *Env, users map[string]int) ([]phabIdProposal, error) {
+	candidates := make(map[string][]*cache.IdentityCache)
+
+	ids := env.backend.AllIdentityIds()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		i, err := env.backend.ResolveIdentity(id)
+		if err != nil {
+			return nil, err
+		}
+		if i.Email() == "" {
+			continue
+		}
+
+		phabID, err := env.backend.LookupPhabID(i.Email())
+		if err != nil {
+			env.err.Printf("looking up the Phabricator ID of %s failed, skipped: %s\n", i.Id().Human(), err)
+			continue
+		}
+		if phabID == "" || phabID == i.PhabID() || users[phabID] == 0 {
+			continue
+		}
+
+		candidates[phabID] = append(candidates[phabID], i)
+	}
+
+	var result []phabIdProposal
+	for phabID, identities := range candidates {
+		// Another identity already set with the Phabricator ID keeps it
+		current, err := env.backend.ResolveIdentityPhabID(phabID)
+		switch {
+		case err == nil:
+			env.err.Printf("%s is already the Phabricator ID of %s, skipped\n", phabID, current.Id().Human())
+			continue
+		case entity.IsErrMultipleMatch(err):
+			env.err.Printf("%s is already the Phabricator ID of several identities, skipped\n", phabID)
+			continue
+		case err != identity.ErrIdentityNotExist:
+			return nil, err
+		}
+
+		if len(identities) > 1 {
+			var names []string
+			for _, i := range identities {
+				names = append(names, i.Id().Human())
+			}
+			env.err.Printf("%s matches several identities (%s), skipped\n", phabID, strings.Join(names, ", "))
+			continue
+		}
+
+		result = append(result, phabIdProposal{identity: identities[0], phabID: phabID})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].phabID < result[j].phabID })
+
+	return result, nil
+}
+
+// phabUserIdentity describes the identity a Phabricator user is shown as
+func phabUserIdentity(env *Env, phabID string) string {
+	i, err := env.backend.ResolveIdentityPhabID(phabID)
+	switch {
+	case err == nil:
+		return fmt.Sprintf("%s (%s)", i.DisplayName(), i.Id().Human())
+	case entity.IsErrMultipleMatch(err):
+		return colors.Red("several identities")
+	default:
+		return colors.Red("no identity")
+	}
+}
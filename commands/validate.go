@@ -6,26 +6,42 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/daedaleanai/git-ticket/bug"
 	"github.com/daedaleanai/git-ticket/validate"
 )
 
+type validateOptions struct {
+	transitions bool
+}
+
 func newValidateCommand() *cobra.Command {
 	env := newEnv()
+	options := validateOptions{}
 
 	cmd := &cobra.Command{
-		Use:      "validate COMMIT...",
-		Short:    "Validate identities and commits signatures.",
+		Use:   "validate [COMMIT...]",
+		Short: "Validate identities and commits signatures.",
+		Long: `Validate identities and commits signatures.
+
+With --transitions the history of every ticket is also replayed to check that each status
+change is a transition of the workflow, made by an identity the workflow allows to make it.`,
 		PreRunE:  loadBackend(env),
 		PostRunE: closeBackend(env),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runValidate(env, args)
+			return runValidate(env, options, args)
 		},
 	}
 
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.BoolVarP(&options.transitions, "transitions", "t", false,
+		"Check the status changes of every ticket against the workflow transitions and permissions")
+
 	return cmd
 }
 
-func runValidate(env *Env, args []string) error {
+func runValidate(env *Env, opts validateOptions, args []string) error {
 	validator, err := validate.NewValidator(env.repo, env.backend)
 	if err != nil {
 		return err
@@ -59,5 +75,37 @@ func runValidate(env *Env, args []string) error {
 		return refErr
 	}
 
+	if opts.transitions {
+		return validateTransitions(env)
+	}
+
+	return nil
+}
+
+// validateTransitions replays the history of every ticket and reports the
+// status changes which aren't transitions of the workflow or which were made
+// by identities the workflow doesn't allow to
+func validateTransitions(env *Env) error {
+	var count int
+
+	for _, id := range env.backend.AllBugsIds() {
+		b, err := env.backend.ResolveBug(id)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range bug.CheckTransitionPermissions(env.backend.Configs(), b.Snapshot()) {
+			count++
+			fmt.Printf("ticket %s\tFAIL: %s -> %s on %s: %s\n", id.Human(), u.From, u.Op.Status,
+				u.Op.Time().Format("2006-01-02 15:04:05"), u.Err)
+		}
+	}
+
+	if count > 0 {
+		return errors.Errorf("%d status change(s) not allowed", count)
+	}
+
+	fmt.Printf("transitions\tOK\n")
+
 	return nil
 }
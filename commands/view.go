@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newViewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "List, save and remove the named queries.",
+		Long: `A view is a query saved under a name, which other queries reference as @name:
+
+git ticket view save triage status:proposed no:label
+git ticket ls @triage label:fpga
+
+Views are saved locally in the git config, or shared with the team in the views config,
+which is synchronized with push and pull. A local view hides the shared one of the same name.`,
+	}
+
+	cmd.AddCommand(newViewLsCommand())
+	cmd.AddCommand(newViewRmCommand())
+	cmd.AddCommand(newViewSaveCommand())
+
+	return cmd
+}
+
+// viewScope describes where a view is stored
+func viewScope(shared bool) string {
+	if shared {
+		return "shared"
+	}
+	return "local"
+}
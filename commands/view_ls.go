@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+func newViewLsCommand() *cobra.Command {
+	env := newEnv()
+
+	cmd := &cobra.Command{
+		Use:      "ls",
+		Short:    "List the views.",
+		PreRunE:  loadBackend(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runViewLs(env)
+		},
+	}
+
+	return cmd
+}
+
+func runViewLs(env *Env) error {
+	views, err := env.backend.Views()
+	if err != nil {
+		return err
+	}
+
+	for i, v := range views {
+		scope := viewScope(v.Shared)
+		// The shared view is hidden by the local one of the same name
+		if i > 0 && views[i-1].Name == v.Name {
+			scope += ", hidden"
+		}
+
+		env.out.Printf("%s\t(%s)\t%s\n", colors.Cyan("@"+v.Name), scope, v.Query)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type viewRmOptions struct {
+	shared bool
+}
+
+func newViewRmCommand() *cobra.Command {
+	env := newEnv()
+	options := viewRmOptions{}
+
+	cmd := &cobra.Command{
+		Use:      "rm NAME",
+		Short:    "Remove a view.",
+		Args:     cobra.ExactArgs(1),
+		PreRunE:  loadBackend(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runViewRm(env, options, args)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.BoolVarP(&options.shared, "shared", "s", false,
+		"Remove the view shared with the team rather than the local one")
+
+	return cmd
+}
+
+func runViewRm(env *Env, opts viewRmOptions, args []string) error {
+	name := strings.ToLower(args[0])
+
+	if err := env.backend.RemoveView(name, opts.shared); err != nil {
+		return err
+	}
+
+	env.out.Printf("View @%s removed (%s)\n", name, viewScope(opts.shared))
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type viewSaveOptions struct {
+	shared bool
+}
+
+func newViewSaveCommand() *cobra.Command {
+	env := newEnv()
+	options := viewSaveOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "save NAME QUERY",
+		Short: "Save a query under a name.",
+		Long: `save stores a query as a view, replacing the view of the same name if any. The name
+starts with a letter followed by letters, digits or dashes, and is case insensitive.
+
+The query can't reference other views. The shared views are published with "git ticket push".`,
+		Example: `Save the unlabeled proposed tickets locally:
+git ticket view save triage status:proposed no:label
+
+Share the open hardware tickets with the team:
+git ticket view save --shared hw "(label:hw OR label:fpga) -status:merged"
+`,
+		Args:     cobra.MinimumNArgs(2),
+		PreRunE:  loadBackend(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runViewSave(env, options, args)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.BoolVarP(&options.shared, "shared", "s", false,
+		"Share the view with the team rather than saving it locally")
+
+	return cmd
+}
+
+func runViewSave(env *Env, opts viewSaveOptions, args []string) error {
+	name := strings.ToLower(args[0])
+
+	if err := env.backend.SetView(name, strings.Join(args[1:], " "), opts.shared); err != nil {
+		return err
+	}
+
+	env.out.Printf("View @%s saved (%s)\n", name, viewScope(opts.shared))
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/bug"
+)
+
+func newWorkflowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workflow",
+		Short: "List, display and act on the ticket workflows.",
+	}
+
+	cmd.AddCommand(newWorkflowGraphCommand())
+	cmd.AddCommand(newWorkflowLsCommand())
+	cmd.AddCommand(newWorkflowMergedCommand())
+	cmd.AddCommand(newWorkflowMigrateCommand())
+	cmd.AddCommand(newWorkflowShowCommand())
+
+	return cmd
+}
+
+// findWorkflow returns the workflow with the given label, the "workflow:"
+// prefix can be omitted
+func findWorkflow(env *Env, label string) (*bug.Workflow, error) {
+	l := bug.Label(label)
+	if !l.IsWorkflow() {
+		l = bug.Label("workflow:" + label)
+	}
+
+	wf := env.backend.Configs().FindWorkflow(l)
+	if wf == nil {
+		return nil, fmt.Errorf("unknown workflow %s", label)
+	}
+
+	return wf, nil
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/bug"
+)
+
+type workflowGraphOptions struct {
+	format string
+	ticket string
+}
+
+func newWorkflowGraphCommand() *cobra.Command {
+	env := newEnv()
+	options := workflowGraphOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "graph [LABEL]",
+		Short: "Render the transitions of a workflow as a graph.",
+		Long: `Render the transitions of a workflow as a Graphviz DOT or a Mermaid graph.
+
+The workflow can be given by its label or be the one of the ticket given with --ticket, in
+which case the current state of the ticket is highlighted.`,
+		Example: `git ticket workflow graph workflow:eng | dot -Tsvg > eng.svg
+git ticket workflow graph --ticket 3f8c2a1 --format mermaid`,
+		PreRunE:  loadBackend(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkflowGraph(env, options, args)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.StringVarP(&options.format, "format", "f", "dot",
+		"Select the output format. Valid values are [dot,mermaid]")
+	flags.StringVarP(&options.ticket, "ticket", "t", "",
+		"Highlight the current state of the given ticket")
+
+	return cmd
+}
+
+func runWorkflowGraph(env *Env, opts workflowGraphOptions, args []string) error {
+	if len(args) > 1 {
+		return errors.New("only one workflow can be rendered at a time")
+	}
+
+	var wf *bug.Workflow
+	var current bug.Status
+
+	if opts.ticket != "" {
+		b, err := env.backend.ResolveBugPrefix(opts.ticket)
+		if err != nil {
+			return err
+		}
+
+		snap := b.Snapshot()
+		current = snap.Status
+
+		wf, err = snap.Workflow(env.backend.Configs())
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(args) == 1 {
+		argWf, err := findWorkflow(env, args[0])
+		if err != nil {
+			return err
+		}
+		if wf != nil && wf != argWf {
+			return fmt.Errorf("the ticket follows %s, not %s", wf.Label(), argWf.Label())
+		}
+		wf = argWf
+	}
+
+	if wf == nil {
+		return errors.New("a workflow label or a ticket is required")
+	}
+
+	switch opts.format {
+	case "dot":
+		env.out.Print(wf.Dot(current))
+	case "mermaid":
+		env.out.Print(wf.Mermaid(current))
+	default:
+		return fmt.Errorf("unknown format %s", opts.format)
+	}
+
+	return nil
+}
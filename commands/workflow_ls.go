@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+func newWorkflowLsCommand() *cobra.Command {
+	env := newEnv()
+
+	cmd := &cobra.Command{
+		Use:      "ls",
+		Short:    "List the workflows.",
+		PreRunE:  loadBackend(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkflowLs(env)
+		},
+	}
+
+	return cmd
+}
+
+func runWorkflowLs(env *Env) error {
+	workflows, err := env.backend.Configs().Workflows()
+	if err != nil {
+		return err
+	}
+
+	for _, wf := range workflows {
+		env.out.Printf("%s\t%d states, %d transitions\n",
+			colors.Cyan(wf.Label()),
+			len(wf.States()),
+			len(wf.Transitions()),
+		)
+	}
+
+	return nil
+}
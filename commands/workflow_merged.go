@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+type workflowMergedOptions struct {
+	branch string
+}
+
+func newWorkflowMergedCommand() *cobra.Command {
+	env := newEnv()
+	options := workflowMergedOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "merged COMMIT...",
+		Short: "Make the automatic transitions of the tickets referenced by merged commits.",
+		Long: `Raise the commit-merged event for the tickets referenced by the given commits.
+
+Tickets are referenced in the commit message by their id prefixed with # or :, as inserted
+by the prepare-commit-msg hook, or with a "Ticket: ID" trailer. Only commits on the main
+branch are considered. The transitions of the ticket workflows triggered by commit-merged
+are then made, for example to set the ticket merged.
+
+This is meant to be run from a git hook, e.g. a post-receive hook on the server or a
+post-merge hook:
+
+git ticket workflow merged $(git rev-list ORIG_HEAD..HEAD)`,
+		PreRunE:  loadBackendEnsureUser(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkflowMerged(env, options, args)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.StringVarP(&options.branch, "branch", "b", "main",
+		"The branch commits must be on")
+
+	return cmd
+}
+
+func runWorkflowMerged(env *Env, opts workflowMergedOptions, args []string) error {
+	if len(args) == 0 {
+		return errors.New("no commit supplied")
+	}
+
+	branchHash, err := env.backend.ResolveRef(opts.branch)
+	if err != nil {
+		return fmt.Errorf("unable to resolve branch %s: %s", opts.branch, err)
+	}
+	branchCommit, err := env.backend.ResolveCommit(branchHash)
+	if err != nil {
+		return err
+	}
+
+	var refs []string
+	seen := make(map[string]bool)
+
+	for _, arg := range args {
+		hash, err := env.backend.ResolveRef(arg)
+		if err != nil {
+			return err
+		}
+		commit, err := env.backend.ResolveCommit(hash)
+		if err != nil {
+			return err
+		}
+
+		if commit.Hash != branchCommit.Hash {
+			onBranch, err := commit.IsAncestor(branchCommit)
+			if err != nil {
+				return err
+			}
+			if !onBranch {
+				env.err.Printf("Warning: commit %.10s is not on %s, skipped\n", hash, opts.branch)
+				continue
+			}
+		}
+
+		for _, ref := range bug.TicketReferences(commit.Message) {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	for _, ref := range refs {
+		b, err := env.backend.ResolveBugPrefix(ref)
+		if err != nil {
+			env.err.Printf("Warning: ticket %s: %s\n", ref, err)
+			continue
+		}
+
+		from := b.Snapshot().Status
+
+		ops, err := b.FireEvent(bug.CommitMergedEvent)
+		if err != nil {
+			env.err.Printf("Warning: ticket %s: automatic transition not made: %s\n", b.Id().Human(), err)
+		}
+		if len(ops) == 0 {
+			continue
+		}
+
+		if err := b.Commit(); err != nil {
+			return err
+		}
+
+		env.out.Printf("%s %s -> %s\n", colors.Cyan(b.Id().Human()), from, b.Snapshot().Status)
+	}
+
+	return nil
+}
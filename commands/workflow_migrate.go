@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/bug"
+	"github.com/daedaleanai/git-ticket/cache"
+	_select "github.com/daedaleanai/git-ticket/commands/select"
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+type workflowMigrateOptions struct {
+	to      string
+	mapping []string
+	dryRun  bool
+}
+
+func newWorkflowMigrateCommand() *cobra.Command {
+	env := newEnv()
+	options := workflowMigrateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "migrate [ID | QUERY]",
+		Short: "Move tickets to another workflow.",
+		Long: `Move tickets to another workflow.
+
+The workflow label of each ticket is replaced and its status kept, unless --map gives
+another status for it. Every ticket must end up in a state of the new workflow, if one
+doesn't nothing is changed. The changes to a ticket are stored as a single commit.
+
+The tickets are given by ID or by query, the selected ticket is used if neither is.`,
+		Example: `git ticket workflow migrate 3f8c2a1 --to workflow:eng --map done=merged
+git ticket workflow migrate label:workflow:qa status:inprogress --to workflow:eng --dry-run`,
+		PreRunE:  loadBackendEnsureUser(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkflowMigrate(env, options, args)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+
+	flags.StringVarP(&options.to, "to", "t", "",
+		"The workflow to move the tickets to")
+	flags.StringSliceVarP(&options.mapping, "map", "m", nil,
+		"Map a status of the old workflow to one of the new workflow, as old=new")
+	flags.BoolVarP(&options.dryRun, "dry-run", "n", false,
+		"Report what would change without changing anything")
+
+	return cmd
+}
+
+func runWorkflowMigrate(env *Env, opts workflowMigrateOptions, args []string) error {
+	if opts.to == "" {
+		return errors.New("the workflow to migrate to is required, use --to")
+	}
+
+	to, err := findWorkflow(env, opts.to)
+	if err != nil {
+		return err
+	}
+
+	mapping, err := parseStatusMapping(opts.mapping)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := resolveMigrateTickets(env, args)
+	if err != nil {
+		return err
+	}
+
+	// Plan every migration first so that nothing is changed if one fails
+	var invalid int
+	for _, b := range tickets {
+		snap := b.Snapshot()
+		m, err := bug.PlanWorkflowMigration(snap, to, mapping)
+
+		switch {
+		case err != nil:
+			invalid++
+			env.out.Printf("%s %s/%s: %s\n", colors.Cyan(b.Id().Human()), m.FromWorkflow, m.FromStatus, colors.Red(err))
+		case m.IsNoop():
+			env.out.Printf("%s %s/%s: unchanged\n", colors.Cyan(b.Id().Human()), m.FromWorkflow, m.FromStatus)
+		default:
+			env.out.Printf("%s %s/%s -> %s/%s\n", colors.Cyan(b.Id().Human()), m.FromWorkflow, m.FromStatus, m.ToWorkflow, m.ToStatus)
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d ticket(s) would end up in an invalid state, nothing was changed", invalid)
+	}
+
+	if opts.dryRun {
+		return nil
+	}
+
+	var migrated int
+	for _, b := range tickets {
+		m, err := b.MigrateWorkflow(to, mapping)
+		if err != nil {
+			return err
+		}
+		if m.IsNoop() {
+			continue
+		}
+		if err := b.Commit(); err != nil {
+			return err
+		}
+		migrated++
+	}
+
+	env.out.Printf("%d ticket(s) migrated to %s\n", migrated, to.Label())
+
+	return nil
+}
+
+// parseStatusMapping parses old=new status pairs
+func parseStatusMapping(pairs []string) (map[bug.Status]bug.Status, error) {
+	mapping := make(map[bug.Status]bug.Status)
+
+	for _, pair := range pairs {
+		split := strings.SplitN(pair, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid status mapping %q, expected old=new", pair)
+		}
+
+		from, err := bug.ParseStatus(split[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid status mapping %q: %s", pair, err)
+		}
+		to, err := bug.ParseStatus(split[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid status mapping %q: %s", pair, err)
+		}
+
+		if _, ok := mapping[from]; ok {
+			return nil, fmt.Errorf("status %s is mapped more than once", from)
+		}
+		mapping[from] = to
+	}
+
+	return mapping, nil
+}
+
+// resolveMigrateTickets returns the ticket given by id, the tickets matching the
+// query or the selected ticket
+func resolveMigrateTickets(env *Env, args []string) ([]*cache.BugCache, error) {
+	if len(args) == 0 {
+		b, _, err := _select.ResolveBug(env.backend, args)
+		if err != nil {
+			return nil, err
+		}
+		return []*cache.BugCache{b}, nil
+	}
+
+	if len(args) == 1 {
+		b, err := env.backend.ResolveBugPrefix(args[0])
+		if err == nil {
+			return []*cache.BugCache{b}, nil
+		}
+		if err != bug.ErrBugNotExist {
+			return nil, err
+		}
+	}
+
+	q, err := env.backend.ParseQuery(strings.Join(args, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*cache.BugCache
+	for _, id := range env.backend.QueryBugs(q) {
+		b, err := env.backend.ResolveBug(id)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, b)
+	}
+
+	if len(tickets) == 0 {
+		return nil, errors.New("no ticket matches the query")
+	}
+
+	return tickets, nil
+}
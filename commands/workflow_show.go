@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+func newWorkflowShowCommand() *cobra.Command {
+	env := newEnv()
+
+	cmd := &cobra.Command{
+		Use:      "show LABEL",
+		Short:    "Display the states, transitions and hooks of a workflow.",
+		PreRunE:  loadBackend(env),
+		PostRunE: closeBackend(env),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkflowShow(env, args)
+		},
+	}
+
+	return cmd
+}
+
+func runWorkflowShow(env *Env, args []string) error {
+	if len(args) != 1 {
+		return errors.New("a single workflow label is required")
+	}
+
+	wf, err := findWorkflow(env, args[0])
+	if err != nil {
+		return err
+	}
+
+	env.out.Printf("%s\n", colors.Cyan(wf.Label()))
+	env.out.Printf("initial state: %s\n", wf.InitialState())
+
+	states := make([]string, 0)
+	for _, s := range wf.States() {
+		states = append(states, s.String())
+	}
+	env.out.Printf("states: %s\n", strings.Join(states, ", "))
+
+	env.out.Printf("transitions:\n")
+	for _, t := range wf.Transitions() {
+		env.out.Printf("  %s -> %s\n", t.Start(), t.End())
+
+		if guards := t.Guards(); len(guards) > 0 {
+			names := make([]string, len(guards))
+			for i, g := range guards {
+				names[i] = g.String()
+			}
+			env.out.Printf("    guards: %s\n", strings.Join(names, ", "))
+		}
+		if t.Trigger() != "" {
+			env.out.Printf("    automatic on: %s\n", t.Trigger())
+		}
+		if p := t.Permission(); !p.IsEmpty() {
+			env.out.Printf("    allowed: %s\n", p)
+		}
+		if t.Hook() != "" {
+			env.out.Printf("    hook: %s (timeout %s)\n", t.Hook(), t.HookTimeout())
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/daedaleanai/git-ticket/repository"
@@ -10,6 +11,9 @@ const configRefPrefix = "refs/configs/"
 const configConflictRefPattern = "refs/conflicts/config-%s-%s"
 const configRemoteRefPattern = "refs/remotes/%s/configs/"
 
+// ErrConfigNotFound is returned when the requested configuration doesn't exist
+var ErrConfigNotFound = errors.New("config not found")
+
 // List configurations stored in git
 func ListConfigs(repo repository.ClockedRepo) ([]string, error) {
 	refs, err := repo.ListRefs(configRefPrefix)
@@ -94,12 +98,47 @@ func SetConfig(repo repository.ClockedRepo, name string, config []byte) error {
 
 // Get the named configuration data
 func GetConfig(repo repository.ClockedRepo, name string) ([]byte, error) {
+	data, _, err := GetConfigVersion(repo, name)
+	return data, err
+}
+
+// GetConfigVersion returns the named configuration data along with its version,
+// the hash of the commit it was read from
+func GetConfigVersion(repo repository.ClockedRepo, name string) ([]byte, repository.Hash, error) {
 	refName := configRefPrefix + name
+	exists, err := repo.RefExist(refName)
+	if err != nil {
+		return nil, "", fmt.Errorf("cache: failed to determine if ref %s exists: %s", refName, err)
+	}
+	if !exists {
+		return nil, "", ErrConfigNotFound
+	}
+
 	commitHash, err := repo.ResolveRef(refName)
 	if err != nil {
-		return nil, fmt.Errorf("cache: failed to resolve ref %s: %s", refName, err)
+		return nil, "", fmt.Errorf("cache: failed to resolve ref %s: %s", refName, err)
 	}
 
+	data, err := readConfigCommit(repo, refName, commitHash)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, commitHash, nil
+}
+
+// GetConfigAt returns the named configuration data as it was at the given
+// version, as returned by GetConfigVersion
+func GetConfigAt(repo repository.ClockedRepo, name string, version repository.Hash) ([]byte, error) {
+	if !version.IsValid() {
+		return nil, fmt.Errorf("invalid version %q of config %s", version, name)
+	}
+
+	return readConfigCommit(repo, configRefPrefix+name, version)
+}
+
+// readConfigCommit returns the configuration data stored in the given commit
+func readConfigCommit(repo repository.ClockedRepo, refName string, commitHash repository.Hash) ([]byte, error) {
 	treeHash, err := repo.GetTreeHash(commitHash)
 	if err != nil {
 		return nil, fmt.Errorf("cache: failed to get the tree for commit %s (ref: %s): %s", commitHash, refName, err)
@@ -124,8 +163,8 @@ func GetConfig(repo repository.ClockedRepo, name string) ([]byte, error) {
 		}
 	}
 	return nil, fmt.Errorf(
-		`cache: failed to find "config.json" blob in the tree corresponding to the HEAD of %s`,
-		refName)
+		`cache: failed to find "config.json" blob in the tree of commit %s (ref: %s)`,
+		commitHash, refName)
 }
 
 // UpdateConfigs fetches config data from the remote and updates the local references.
@@ -249,6 +249,7 @@ const checklistPreamble = `# %s
 
 # Leave lines starting with '#' unchanged.
 # States in [] can be: TBD, PASSED, FAILED or NA. Abbreviations accepted.
+# Questions expecting an answer take it after the state, e.g. [PASSED] yes.
 # Any text entered between the question and state will be saved as a comment.
 # Saving an empty (or invalid format) aborts the operation.
 
@@ -269,9 +270,13 @@ func ChecklistEditorInput(repo repository.RepoCommon, checklist bug.Checklist) (
 	for sn, s := range checklist.Sections {
 		template = template + fmt.Sprintf("#\n#### %s ####\n#\n", s.Title)
 		for qn, q := range s.Questions {
-			template = template + fmt.Sprintf("# %d.%d : %s\n", sn+1, qn+1, q.Question)
+			template = template + fmt.Sprintf("# %d.%d : %s%s\n", sn+1, qn+1, q.Question, questionHints(checklist, q))
 			template = template + fmt.Sprintf("%s\n", q.Comment)
-			template = template + fmt.Sprintf("[%s]\n", q.State.ShortString())
+			if q.Kind != bug.PlainQuestion {
+				template = template + strings.TrimSpace(fmt.Sprintf("[%s] %s", q.State.ShortString(), q.Value)) + "\n"
+			} else {
+				template = template + fmt.Sprintf("[%s]\n", q.State.ShortString())
+			}
 		}
 	}
 
@@ -296,6 +301,7 @@ func ChecklistEditorInput(repo repository.RepoCommon, checklist bug.Checklist) (
 
 	questionSearch, _ := regexp.Compile(`^# (\d+)\.(\d+) : (\w+)`)
 	stateSearch, _ := regexp.Compile(`^\[(.+)\]$`)
+	stateValueSearch, _ := regexp.Compile(`^\[([^\]]+)\](?:\s+(.*))?$`)
 
 	for l, line := range lines {
 		if !inComment {
@@ -310,6 +316,10 @@ func ChecklistEditorInput(repo repository.RepoCommon, checklist bug.Checklist) (
 					// unexpected question number
 					return checklistChanged, fmt.Errorf("checklist parse error (question number), line %d. %s", l, checklistParseFailMessage)
 				}
+				if nextS > len(checklist.Sections) {
+					// more questions than in the checklist
+					return checklistChanged, fmt.Errorf("checklist parse error (section number), line %d. %s", l, checklistParseFailMessage)
+				}
 				inComment = true
 				commentText = ""
 			} else if nextQ != 1 {
@@ -317,8 +327,17 @@ func ChecklistEditorInput(repo repository.RepoCommon, checklist bug.Checklist) (
 				return checklistChanged, fmt.Errorf("checklist parse error (question line), line %d. %s", l, checklistParseFailMessage)
 			}
 		} else {
-			if stateSearch.MatchString(line) {
-				newState, err := bug.StateFromString(stateSearch.FindStringSubmatch(line)[1])
+			question := &checklist.Sections[nextS-1].Questions[nextQ-1]
+
+			var matches []string
+			if question.Kind != bug.PlainQuestion {
+				matches = stateValueSearch.FindStringSubmatch(line)
+			} else {
+				matches = stateSearch.FindStringSubmatch(line)
+			}
+
+			if matches != nil {
+				newState, err := bug.StateFromString(matches[1])
 				if err != nil {
 					// something is wrong with the format
 					return checklistChanged, fmt.Errorf("checklist parse error (invalid state), line %d. %s", l, checklistParseFailMessage)
@@ -334,6 +353,17 @@ func ChecklistEditorInput(repo repository.RepoCommon, checklist bug.Checklist) (
 					checklist.Sections[nextS-1].Questions[nextQ-1].State = newState
 					checklistChanged = true
 				}
+				// check and save answer
+				if question.Kind != bug.PlainQuestion {
+					newValue := strings.TrimSpace(matches[2])
+					if question.Kind == bug.YesNoQuestion {
+						newValue = strings.ToLower(newValue)
+					}
+					if question.Value != newValue {
+						question.Value = newValue
+						checklistChanged = true
+					}
+				}
 				nextQ++
 				if nextQ > len(checklist.Sections[nextS-1].Questions) {
 					nextS++
@@ -351,31 +381,73 @@ func ChecklistEditorInput(repo repository.RepoCommon, checklist bug.Checklist) (
 		return checklistChanged, fmt.Errorf("checklist parse error, section/question count. %s", checklistParseFailMessage)
 	}
 
+	if err := checklist.Validate(); err != nil {
+		return checklistChanged, fmt.Errorf("invalid checklist, %s. %s", err, checklistParseFailMessage)
+	}
+
 	os.Remove(checklistBackupFile)
 
 	return checklistChanged, nil
 }
 
+// questionHints returns the answer a question expects and the states in which
+// it needs a comment, to be shown after it in the editor
+func questionHints(checklist bug.Checklist, q bug.ChecklistQuestion) string {
+	var hints []string
+	if hint := q.Hint(); hint != "" {
+		hints = append(hints, hint)
+	}
+
+	var states []string
+	for _, s := range []bug.ChecklistState{bug.TBD, bug.Passed, bug.Failed, bug.NotApplicable} {
+		if q.CommentRequiredFor(checklist.CommentRequired, s) {
+			states = append(states, s.String())
+		}
+	}
+	if len(states) > 0 {
+		hints = append(hints, "comment required if "+strings.Join(states, " or "))
+	}
+
+	if len(hints) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(hints, "; ") + ")"
+}
+
 const queryTemplate = `%s
 
 # Please edit the bug query.
 # Lines starting with '#' will be ignored, and an empty query aborts the operation.
 #
-# Example: status:open author:"rené descartes" sort:edit
+# Example: status:proposed author:"rené descartes" sort:edit
 #
 # Valid filters are:
 #
-# - status:open, status:closed
+# - status:<status>
 # - author:<query>
 # - title:<title>
 # - label:<label>
+# - <words>, searched in the title, comments and review comments
+# - created:<date>, edited:<date>, e.g. created:>2026-01-01, edited:<7d, created:2026-03..2026-06
+# - metadata:<key>=<value>, metadata:<key>
+# - checklist:<state>, checklist:label:<label>=<state>, e.g. checklist:failed
+# - review:<status>, review:none, reviewer:<query>, e.g. review:needs-revision
 # - no:label
 #
+# Operators
+#
+# - qualifiers separated by spaces must all match, AND can also be written
+# - label:hw OR label:fpga, either must match
+# - NOT status:merged or -status:merged, must not match
+# - (label:hw OR label:fpga) status:proposed, parentheses group the operators
+# - @<view>, the query saved with "git ticket view save", e.g. @triage label:fpga
+#
 # Sorting
 #
 # - sort:id, sort:id-desc, sort:id-asc
 # - sort:creation, sort:creation-desc, sort:creation-asc
 # - sort:edit, sort:edit-desc, sort:edit-asc
+# - sort:metadata.<key>, sort:metadata.<key>-desc, sort:metadata.<key>-asc
 #
 # Notes
 #
@@ -85,6 +85,23 @@ loop:
 	}
 }
 
+// PromptConfirm is a yes or no question, no by default.
+func PromptConfirm(prompt string) (bool, error) {
+	_, _ = fmt.Fprintf(os.Stderr, "%s [y/N]: ", prompt)
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
 // PromptPassword is a specialized text input that doesn't display the characters entered.
 func PromptPassword(prompt, name string, validators ...PromptValidator) (string, error) {
 	termState, err := terminal.GetState(int(syscall.Stdin))
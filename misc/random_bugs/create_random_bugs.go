@@ -225,5 +225,5 @@ func labels(b bug.Interface, p identity.Interface, timestamp int64) {
 	// ignore error
 	// if the randomisation produce no changes, no op
 	// is added to the bug
-	_, _, _ = bug.ChangeLabels(b, p, timestamp, added, removed)
+	_, _, _ = bug.ChangeLabels(b, p, timestamp, added, removed, nil)
 }
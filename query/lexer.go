@@ -6,9 +6,41 @@ import (
 	"unicode"
 )
 
+type tokenKind int
+
+const (
+	_ tokenKind = iota
+	tokenKindKV
+	tokenKindOpen
+	tokenKindClose
+	tokenKindAnd
+	tokenKindOr
+	tokenKindNot
+	tokenKindSearch
+	tokenKindView
+)
+
 type token struct {
+	kind tokenKind
+
+	// KV
 	qualifier string
 	value     string
+
+	// Search, View
+	text string
+}
+
+func newTokenKV(qualifier, value string) token {
+	return token{kind: tokenKindKV, qualifier: qualifier, value: value}
+}
+
+func newTokenSearch(text string) token {
+	return token{kind: tokenKindSearch, text: text}
+}
+
+func newTokenView(name string) token {
+	return token{kind: tokenKindView, text: name}
 }
 
 // tokenize parse and break a input into tokens ready to be
@@ -20,24 +52,81 @@ func tokenize(query string) ([]token, error) {
 	}
 
 	var tokens []token
+	depth := 0
+
 	for _, field := range fields {
-		split := strings.Split(field, ":")
-		if len(split) != 2 {
-			return nil, fmt.Errorf("can't tokenize \"%s\"", field)
+		// Leading opening parenthesis and negations
+		for len(field) > 0 {
+			if field[0] == '(' {
+				tokens = append(tokens, token{kind: tokenKindOpen})
+				depth++
+			} else if field[0] == '-' {
+				tokens = append(tokens, token{kind: tokenKindNot})
+			} else {
+				break
+			}
+			field = field[1:]
 		}
 
-		if len(split[0]) == 0 {
-			return nil, fmt.Errorf("can't tokenize \"%s\": empty qualifier", field)
+		// Trailing closing parenthesis, as long as they close a group so that
+		// values like title:foo(bar) keep working
+		closing := 0
+		for len(field) > 0 && field[len(field)-1] == ')' && closing < depth {
+			field = field[:len(field)-1]
+			closing++
 		}
-		if len(split[1]) == 0 {
-			return nil, fmt.Errorf("empty value for qualifier \"%s\"", split[0])
+		depth -= closing
+
+		switch field {
+		case "":
+		case "AND":
+			tokens = append(tokens, token{kind: tokenKindAnd})
+		case "OR":
+			tokens = append(tokens, token{kind: tokenKindOr})
+		case "NOT":
+			tokens = append(tokens, token{kind: tokenKindNot})
+		default:
+			// Saved view
+			if field[0] == '@' {
+				if len(field) == 1 {
+					return nil, fmt.Errorf("empty view name")
+				}
+				tokens = append(tokens, newTokenView(field[1:]))
+				break
+			}
+
+			// Free text, either quoted or without qualifier
+			if isQuote([]rune(field)[0]) || !strings.Contains(field, ":") {
+				if field[0] == ')' {
+					return nil, fmt.Errorf("unmatched closing parenthesis")
+				}
+				text := removeQuote(field)
+				if len(text) == 0 || isQuote([]rune(text)[0]) {
+					return nil, fmt.Errorf("can't tokenize \"%s\"", field)
+				}
+				tokens = append(tokens, newTokenSearch(text))
+				break
+			}
+
+			// Only the first colon separates the qualifier, the value may hold
+			// more of them (ex: label:checklist:code)
+			split := strings.SplitN(field, ":", 2)
+
+			if len(split[0]) == 0 {
+				return nil, fmt.Errorf("can't tokenize \"%s\": empty qualifier", field)
+			}
+			if len(split[1]) == 0 {
+				return nil, fmt.Errorf("empty value for qualifier \"%s\"", split[0])
+			}
+
+			tokens = append(tokens, newTokenKV(split[0], removeQuote(split[1])))
 		}
 
-		tokens = append(tokens, token{
-			qualifier: split[0],
-			value:     removeQuote(split[1]),
-		})
+		for i := 0; i < closing; i++ {
+			tokens = append(tokens, token{kind: tokenKindClose})
+		}
 	}
+
 	return tokens, nil
 }
 
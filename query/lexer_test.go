@@ -11,32 +11,100 @@ func TestTokenize(t *testing.T) {
 		input  string
 		tokens []token
 	}{
-		{"gibberish", nil},
 		{"status:", nil},
 		{":value", nil},
 
-		{"status:open", []token{{"status", "open"}}},
-		{"status:closed", []token{{"status", "closed"}}},
+		{"status:open", []token{newTokenKV("status", "open")}},
+		{"status:closed", []token{newTokenKV("status", "closed")}},
 
-		{"author:rene", []token{{"author", "rene"}}},
-		{`author:"René Descartes"`, []token{{"author", "René Descartes"}}},
+		{"author:rene", []token{newTokenKV("author", "rene")}},
+		{`author:"René Descartes"`, []token{newTokenKV("author", "René Descartes")}},
 
 		{
 			`status:open status:closed author:rene author:"René Descartes"`,
 			[]token{
-				{"status", "open"},
-				{"status", "closed"},
-				{"author", "rene"},
-				{"author", "René Descartes"},
+				newTokenKV("status", "open"),
+				newTokenKV("status", "closed"),
+				newTokenKV("author", "rene"),
+				newTokenKV("author", "René Descartes"),
 			},
 		},
 
 		// quotes
-		{`key:"value value"`, []token{{"key", "value value"}}},
-		{`key:'value value'`, []token{{"key", "value value"}}},
+		{`key:"value value"`, []token{newTokenKV("key", "value value")}},
+		{`key:'value value'`, []token{newTokenKV("key", "value value")}},
 		// unmatched quotes
 		{`key:'value value`, nil},
 		{`key:value value'`, nil},
+
+		// colons in values
+		{"label:checklist:code", []token{newTokenKV("label", "checklist:code")}},
+		{`label:"checklist:code"`, []token{newTokenKV("label", "checklist:code")}},
+
+		// operators
+		{
+			`(label:hw OR label:fpga) -status:merged NOT assignee:alice AND title:"a (b)"`,
+			[]token{
+				{kind: tokenKindOpen},
+				newTokenKV("label", "hw"),
+				{kind: tokenKindOr},
+				newTokenKV("label", "fpga"),
+				{kind: tokenKindClose},
+				{kind: tokenKindNot},
+				newTokenKV("status", "merged"),
+				{kind: tokenKindNot},
+				newTokenKV("assignee", "alice"),
+				{kind: tokenKindAnd},
+				newTokenKV("title", "a (b)"),
+			},
+		},
+		{
+			"( -(label:a) ) title:foo(bar)",
+			[]token{
+				{kind: tokenKindOpen},
+				{kind: tokenKindNot},
+				{kind: tokenKindOpen},
+				newTokenKV("label", "a"),
+				{kind: tokenKindClose},
+				{kind: tokenKindClose},
+				newTokenKV("title", "foo(bar)"),
+			},
+		},
+		{"(label:a", []token{{kind: tokenKindOpen}, newTokenKV("label", "a")}},
+
+		// free text
+		{"gibberish", []token{newTokenSearch("gibberish")}},
+		{
+			`watchdog "timeout: 5s" -(reset) status:open`,
+			[]token{
+				newTokenSearch("watchdog"),
+				newTokenSearch("timeout: 5s"),
+				{kind: tokenKindNot},
+				{kind: tokenKindOpen},
+				newTokenSearch("reset"),
+				{kind: tokenKindClose},
+				newTokenKV("status", "open"),
+			},
+		},
+		{`""`, nil},
+		{`"foo"bar`, nil},
+
+		// views
+		{"@triage", []token{newTokenView("triage")}},
+		{
+			"-@triage (@mine OR label:hw) \"@home\"",
+			[]token{
+				{kind: tokenKindNot},
+				newTokenView("triage"),
+				{kind: tokenKindOpen},
+				newTokenView("mine"),
+				{kind: tokenKindOr},
+				newTokenKV("label", "hw"),
+				{kind: tokenKindClose},
+				newTokenSearch("@home"),
+			},
+		},
+		{"@", nil},
 	}
 
 	for _, tc := range tests {
@@ -2,6 +2,7 @@ package query
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/daedaleanai/git-ticket/bug"
 )
@@ -10,64 +11,413 @@ import (
 //
 // Ex: "status:open author:descartes sort:edit-asc"
 //
+// Qualifiers can be combined with OR, negated with NOT or a leading -, and
+// grouped in parenthesis:
+//
+// Ex: "(label:hw OR label:fpga) -status:merged assignee:alice"
+//
+// Terms without qualifier are searched in the text of the bugs:
+//
+// Ex: "watchdog timeout label:fpga"
+//
 // Supported filter qualifiers and syntax are described in docs/queries.md
+//
+// Only the built-in statuses and the ones of the default workflows are
+// accepted, use ParseWithConfigs to accept the statuses of a repository.
 func Parse(query string) (*Query, error) {
+	return ParseWithConfigs(query, nil)
+}
+
+// ParseWithConfigs parse a query DSL, accepting the statuses of the workflows
+// configured in the repository
+func ParseWithConfigs(query string, configs *bug.ConfigCache) (*Query, error) {
+	return ParseWithViews(query, configs, nil)
+}
+
+// ParseWithViews parse a query DSL, expanding the saved views it references as
+// @name with their query. Views are given by lowercase name and can't
+// reference other views.
+//
+// Ex: "@triage label:fpga"
+//
+// The sorting of a view applies unless the query sets its own.
+func ParseWithViews(query string, configs *bug.ConfigCache, views map[string]string) (*Query, error) {
+	p := &parser{
+		configs: configs,
+		views:   views,
+		query: &Query{
+			OrderBy:        OrderByCreation,
+			OrderDirection: OrderDescending,
+		},
+	}
+
+	expr, err := p.parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	q := p.query
+
+	if !p.sortingDone && p.viewSorting != nil {
+		q.OrderBy = p.viewSorting.OrderBy
+		q.OrderDirection = p.viewSorting.OrderDirection
+		q.OrderKey = p.viewSorting.OrderKey
+	}
+
+	// A query without operators is a list of filters, as it has always been
+	if and, ok := expr.(*And); ok {
+		q.Filters = and.Filters
+		q.Expressions = and.Operands
+	} else {
+		q.Expressions = []Expression{expr}
+	}
+
+	return q, nil
+}
+
+// parser builds the expression tree of a query from its tokens:
+//
+//	or    := and ("OR" and)*
+//	and   := unary ("AND"? unary)*
+//	unary := ("NOT" | "-") unary | "(" or ")" | qualifier:value | text | @view
+type parser struct {
+	tokens  []token
+	pos     int
+	configs *bug.ConfigCache
+	views   map[string]string
+	inView  bool
+
+	query       *Query
+	sortingDone bool
+	// the query holding the first sorting set by a view
+	viewSorting *Query
+}
+
+// parse tokenizes a query and builds its expression
+func (p *parser) parse(query string) (Expression, error) {
 	tokens, err := tokenize(query)
 	if err != nil {
 		return nil, err
 	}
+	p.tokens = tokens
 
-	q := &Query{
-		OrderBy:        OrderByCreation,
-		OrderDirection: OrderDescending,
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		// parseOr only stops early on a closing parenthesis
+		return nil, fmt.Errorf("unmatched closing parenthesis")
 	}
-	sortingDone := false
 
-	for _, t := range tokens {
-		switch t.qualifier {
-		case "status", "state":
-			status, err := bug.StatusFromString(t.value)
-			if err != nil {
-				return nil, err
-			}
-			q.Status = append(q.Status, status)
-		case "author":
-			q.Author = append(q.Author, t.value)
-		case "actor":
-			q.Actor = append(q.Actor, t.value)
-		case "assignee":
-			q.Assignee = append(q.Assignee, t.value)
-		case "participant":
-			q.Participant = append(q.Participant, t.value)
-		case "label":
-			q.Label = append(q.Label, t.value)
-		case "title":
-			q.Title = append(q.Title, t.value)
-		case "no":
-			switch t.value {
-			case "label":
-				q.NoLabel = true
-			default:
-				return nil, fmt.Errorf("unknown \"no\" filter \"%s\"", t.value)
+	return expr, nil
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*And{first}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokenKindOr {
+			break
+		}
+		p.pos++
+
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+
+	or := &Or{}
+	for _, and := range operands {
+		if and.isEmpty() {
+			return nil, fmt.Errorf("missing operand for OR")
+		}
+		or.Operands = append(or.Operands, and.simplify())
+	}
+	return or, nil
+}
+
+func (p *parser) parseAnd() (*And, error) {
+	and := &And{}
+
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokenKindOr || t.kind == tokenKindClose {
+			return and, nil
+		}
+
+		switch t.kind {
+		case tokenKindAnd:
+			p.pos++
+			next, ok := p.peek()
+			if and.isEmpty() || !ok || next.kind == tokenKindOr || next.kind == tokenKindClose || next.kind == tokenKindAnd {
+				return nil, fmt.Errorf("missing operand for AND")
 			}
-		case "sort":
-			if sortingDone {
-				return nil, fmt.Errorf("multiple sorting")
+
+		case tokenKindKV:
+			// The qualifiers are combined as in a query without operators
+			p.pos++
+			if err := p.addQualifier(&and.Filters, t); err != nil {
+				return nil, err
 			}
-			err = parseSorting(q, t.value)
+
+		case tokenKindSearch:
+			p.pos++
+			and.Search = append(and.Search, t.text)
+
+		default:
+			operand, err := p.parseOperand()
 			if err != nil {
 				return nil, err
 			}
-			sortingDone = true
+			and.Operands = append(and.Operands, operand)
+		}
+	}
+}
 
+// parseOperand parses a negation, a group or a single qualifier
+func (p *parser) parseOperand() (Expression, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("missing operand")
+	}
+	p.pos++
+
+	switch t.kind {
+	case tokenKindNot:
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Operand: operand}, nil
+
+	case tokenKindOpen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || next.kind != tokenKindClose {
+			return nil, fmt.Errorf("unmatched opening parenthesis")
+		}
+		p.pos++
+
+		if and, ok := expr.(*And); ok {
+			if and.isEmpty() {
+				return nil, fmt.Errorf("empty parenthesis")
+			}
+			return and.simplify(), nil
+		}
+		return expr, nil
+
+	case tokenKindKV:
+		if t.qualifier == "sort" {
+			return nil, fmt.Errorf("sorting can't be combined with operators")
+		}
+		and := &And{}
+		if err := p.addQualifier(&and.Filters, t); err != nil {
+			return nil, err
+		}
+		return and, nil
+
+	case tokenKindSearch:
+		return &And{Filters: Filters{Search: []string{t.text}}}, nil
+
+	case tokenKindView:
+		return p.parseView(t.text)
+
+	default:
+		return nil, fmt.Errorf("missing operand")
+	}
+}
+
+// parseView parses the query of a saved view as a group
+func (p *parser) parseView(name string) (Expression, error) {
+	if p.inView {
+		return nil, fmt.Errorf("views can't reference other views: @%s", name)
+	}
+
+	query, ok := p.views[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown view @%s", name)
+	}
+
+	sub := &parser{
+		configs: p.configs,
+		inView:  true,
+		query: &Query{
+			OrderBy:        OrderByCreation,
+			OrderDirection: OrderDescending,
+		},
+	}
+
+	expr, err := sub.parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("view @%s: %s", name, err)
+	}
+
+	if sub.sortingDone && p.viewSorting == nil {
+		p.viewSorting = sub.query
+	}
+
+	if and, ok := expr.(*And); ok {
+		return and.simplify(), nil
+	}
+	return expr, nil
+}
+
+// addQualifier adds the filter of a qualifier to filters, or sets the sorting
+// of the query
+func (p *parser) addQualifier(filters *Filters, t token) error {
+	switch t.qualifier {
+	case "status", "state":
+		status, err := p.configs.StatusFromString(t.value)
+		if err != nil {
+			return err
+		}
+		filters.Status = append(filters.Status, status)
+	case "author":
+		filters.Author = append(filters.Author, t.value)
+	case "actor":
+		filters.Actor = append(filters.Actor, t.value)
+	case "assignee":
+		filters.Assignee = append(filters.Assignee, t.value)
+	case "participant":
+		filters.Participant = append(filters.Participant, t.value)
+	case "label":
+		filters.Label = append(filters.Label, t.value)
+	case "title":
+		filters.Title = append(filters.Title, t.value)
+	case "created", "edited":
+		r, err := parseTimeRange(t.value)
+		if err != nil {
+			return err
+		}
+		if t.qualifier == "created" {
+			filters.Created = append(filters.Created, r)
+		} else {
+			filters.Edited = append(filters.Edited, r)
+		}
+	case "metadata":
+		filter := MetadataFilter{Key: t.value}
+		if i := strings.Index(t.value, "="); i >= 0 {
+			filter = MetadataFilter{Key: t.value[:i], Value: t.value[i+1:], HasValue: true}
+		}
+		if filter.Key == "" {
+			return fmt.Errorf("empty metadata key in \"%s\"", t.value)
+		}
+		filters.Metadata = append(filters.Metadata, filter)
+	case "checklist":
+		filter, err := parseChecklistFilter(t.value)
+		if err != nil {
+			return err
+		}
+		filters.Checklist = append(filters.Checklist, filter)
+	case "review":
+		status := strings.ToLower(t.value)
+		if status != "none" && !isReviewStatus(status) {
+			return fmt.Errorf("unknown review status \"%s\", expected none or one of %s",
+				t.value, strings.Join(bug.ReviewStatuses, ", "))
+		}
+		filters.Review = append(filters.Review, status)
+	case "reviewer":
+		filters.Reviewer = append(filters.Reviewer, t.value)
+	case "no":
+		switch t.value {
+		case "label":
+			filters.NoLabel = true
 		default:
-			return nil, fmt.Errorf("unknown qualifier \"%s\"", t.qualifier)
+			return fmt.Errorf("unknown \"no\" filter \"%s\"", t.value)
+		}
+	case "sort":
+		if p.sortingDone {
+			return fmt.Errorf("multiple sorting")
 		}
+		if err := parseSorting(p.query, t.value); err != nil {
+			return err
+		}
+		p.sortingDone = true
+
+	default:
+		return fmt.Errorf("unknown qualifier \"%s\"", t.qualifier)
 	}
-	return q, nil
+
+	return nil
+}
+
+// parseChecklistFilter parses the value of a checklist qualifier, a state
+// (ex: failed) or a checklist label and a state (ex: label:checklist:code=passed)
+func parseChecklistFilter(value string) (ChecklistFilter, error) {
+	var filter ChecklistFilter
+
+	state := value
+	if strings.HasPrefix(value, "label:") {
+		i := strings.LastIndex(value, "=")
+		if i < 0 {
+			return ChecklistFilter{}, fmt.Errorf("missing checklist state in \"%s\", expected label:<label>=<state>", value)
+		}
+		filter.Label = bug.Label(strings.TrimPrefix(value[:i], "label:"))
+		state = value[i+1:]
+
+		if !filter.Label.IsChecklist() {
+			return ChecklistFilter{}, fmt.Errorf("\"%s\" isn't a checklist label", filter.Label)
+		}
+	}
+
+	var err error
+	filter.State, err = bug.StateFromString(state)
+	if err != nil || state == "" {
+		return ChecklistFilter{}, fmt.Errorf("unknown checklist state \"%s\", expected tbd, passed, failed or na", state)
+	}
+
+	return filter, nil
+}
+
+func isReviewStatus(status string) bool {
+	for _, s := range bug.ReviewStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
 }
 
 func parseSorting(q *Query, value string) error {
+	// default ASC
+	if strings.HasPrefix(value, "metadata.") {
+		key := strings.TrimPrefix(value, "metadata.")
+		q.OrderDirection = OrderAscending
+		if strings.HasSuffix(key, "-asc") {
+			key = strings.TrimSuffix(key, "-asc")
+		} else if strings.HasSuffix(key, "-desc") {
+			key = strings.TrimSuffix(key, "-desc")
+			q.OrderDirection = OrderDescending
+		}
+		if key == "" {
+			return fmt.Errorf("empty metadata key in sorting %s", value)
+		}
+		q.OrderBy = OrderByMetadata
+		q.OrderKey = key
+		return nil
+	}
+
 	switch value {
 	// default ASC
 	case "id-desc":
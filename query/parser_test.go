@@ -2,6 +2,7 @@ package query
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -13,7 +14,12 @@ func TestParse(t *testing.T) {
 		input  string
 		output *Query
 	}{
-		{"gibberish", nil},
+		{"gibberish", &Query{
+			Filters: Filters{Search: []string{"gibberish"}},
+		}},
+		{`watchdog "timeout reset" label:fpga`, &Query{
+			Filters: Filters{Search: []string{"watchdog", "timeout reset"}, Label: []string{"fpga"}},
+		}},
 		{"status:", nil},
 		{":value", nil},
 
@@ -57,9 +63,61 @@ func TestParse(t *testing.T) {
 			Filters: Filters{NoLabel: true},
 		}},
 
+		{"created:>2026-01-01", &Query{
+			Filters: Filters{Created: []TimeRange{{After: time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)}}},
+		}},
+		{"edited:2026-03..2026-06", &Query{
+			Filters: Filters{Edited: []TimeRange{{
+				After:  time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),
+				Before: time.Date(2026, 7, 1, 0, 0, 0, 0, time.Local),
+			}}},
+		}},
+		{"edited:yesterday", nil},
+
+		{"metadata:github-url=https://github.com/a/b/issues/1", &Query{
+			Filters: Filters{Metadata: []MetadataFilter{
+				{Key: "github-url", Value: "https://github.com/a/b/issues/1", HasValue: true},
+			}},
+		}},
+		{"metadata:origin metadata:import=", &Query{
+			Filters: Filters{Metadata: []MetadataFilter{
+				{Key: "origin"},
+				{Key: "import", HasValue: true},
+			}},
+		}},
+		{"metadata:=value", nil},
+
+		{"checklist:failed checklist:label:checklist:code=passed", &Query{
+			Filters: Filters{Checklist: []ChecklistFilter{
+				{State: bug.Failed},
+				{Label: "checklist:code", State: bug.Passed},
+			}},
+		}},
+		{"checklist:tbd", &Query{
+			Filters: Filters{Checklist: []ChecklistFilter{{State: bug.TBD}}},
+		}},
+		{"checklist:label:checklist:code", nil},
+		{"checklist:label:workflow:hw=passed", nil},
+		{"checklist:broken", nil},
+		{"review:accepted review:None reviewer:bob", &Query{
+			Filters: Filters{Review: []string{"accepted", "none"}, Reviewer: []string{"bob"}},
+		}},
+		{"review:great", nil},
+
 		{"sort:edit", &Query{
 			OrderBy: OrderByEdit,
 		}},
+		{"sort:metadata.origin", &Query{
+			OrderBy:        OrderByMetadata,
+			OrderDirection: OrderAscending,
+			OrderKey:       "origin",
+		}},
+		{"sort:metadata.github-url-desc", &Query{
+			OrderBy:        OrderByMetadata,
+			OrderDirection: OrderDescending,
+			OrderKey:       "github-url",
+		}},
+		{"sort:metadata.", nil},
 		{"sort:unknown", nil},
 
 		{`status:proposed author:"René Descartes" participant:leonhard label:hello label:"Good first issue" sort:edit-desc`,
@@ -90,8 +148,150 @@ func TestParse(t *testing.T) {
 				if tc.output.OrderDirection != 0 {
 					assert.Equal(t, tc.output.OrderDirection, query.OrderDirection)
 				}
+				assert.Equal(t, tc.output.OrderKey, query.OrderKey)
 				assert.Equal(t, tc.output.Filters, query.Filters)
 			}
 		})
 	}
 }
+
+func TestParseExpressions(t *testing.T) {
+	var tests = []struct {
+		input       string
+		filters     Filters
+		expressions []Expression
+	}{
+		{"label:checklist:code", Filters{Label: []string{"checklist:code"}}, nil},
+
+		{
+			"(label:hw OR label:fpga) -status:merged assignee:alice",
+			Filters{Assignee: []string{"alice"}},
+			[]Expression{
+				&Or{Operands: []Expression{
+					&And{Filters: Filters{Label: []string{"hw"}}},
+					&And{Filters: Filters{Label: []string{"fpga"}}},
+				}},
+				&Not{Operand: &And{Filters: Filters{Status: []bug.Status{bug.MergedStatus}}}},
+			},
+		},
+		{
+			"label:hw OR status:proposed author:rene",
+			Filters{},
+			[]Expression{
+				&Or{Operands: []Expression{
+					&And{Filters: Filters{Label: []string{"hw"}}},
+					&And{Filters: Filters{Status: []bug.Status{bug.ProposedStatus}, Author: []string{"rene"}}},
+				}},
+			},
+		},
+		{
+			"NOT (label:a AND (label:b OR label:c)) sort:id",
+			Filters{},
+			[]Expression{
+				&Not{Operand: &And{
+					Filters: Filters{Label: []string{"a"}},
+					Operands: []Expression{&Or{Operands: []Expression{
+						&And{Filters: Filters{Label: []string{"b"}}},
+						&And{Filters: Filters{Label: []string{"c"}}},
+					}}},
+				}},
+			},
+		},
+		{
+			"((label:a)) --label:b",
+			Filters{},
+			[]Expression{
+				&And{Filters: Filters{Label: []string{"a"}}},
+				&Not{Operand: &Not{Operand: &And{Filters: Filters{Label: []string{"b"}}}}},
+			},
+		},
+		{
+			"watchdog -reset (timeout OR hang)",
+			Filters{Search: []string{"watchdog"}},
+			[]Expression{
+				&Not{Operand: &And{Filters: Filters{Search: []string{"reset"}}}},
+				&Or{Operands: []Expression{
+					&And{Filters: Filters{Search: []string{"timeout"}}},
+					&And{Filters: Filters{Search: []string{"hang"}}},
+				}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			query, err := Parse(tc.input)
+			assert.NoError(t, err)
+			if err == nil {
+				assert.Equal(t, tc.filters, query.Filters)
+				assert.Equal(t, tc.expressions, query.Expressions)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"label:a OR", "OR label:a", "label:a OR OR label:b", "AND label:a", "label:a AND",
+		"(label:a", "label:a )", "()", "-", "NOT", "-sort:id", "(sort:id)", "label:a OR sort:id",
+		"(status:unknown)",
+	} {
+		t.Run(input, func(t *testing.T) {
+			query, err := Parse(input)
+			assert.Error(t, err)
+			assert.Nil(t, query)
+		})
+	}
+
+	query, err := Parse("(label:a OR label:b) sort:id-asc")
+	assert.NoError(t, err)
+	assert.Equal(t, OrderById, query.OrderBy)
+	assert.Equal(t, OrderAscending, query.OrderDirection)
+}
+
+func TestParseViews(t *testing.T) {
+	views := map[string]string{
+		"triage": "status:proposed no:label",
+		"hw":     "label:hw OR label:fpga",
+		"recent": "edited:<7d sort:edit",
+		"nested": "@hw",
+		"broken": "label:a OR",
+	}
+
+	query, err := ParseWithViews("@triage author:rene", nil, views)
+	assert.NoError(t, err)
+	assert.Equal(t, Filters{Author: []string{"rene"}}, query.Filters)
+	assert.Equal(t, []Expression{
+		&And{Filters: Filters{Status: []bug.Status{bug.ProposedStatus}, NoLabel: true}},
+	}, query.Expressions)
+
+	query, err = ParseWithViews("-@HW", nil, views)
+	assert.NoError(t, err)
+	assert.Equal(t, []Expression{
+		&Not{Operand: &Or{Operands: []Expression{
+			&And{Filters: Filters{Label: []string{"hw"}}},
+			&And{Filters: Filters{Label: []string{"fpga"}}},
+		}}},
+	}, query.Expressions)
+
+	// The sorting of a view applies unless the query sets its own
+	query, err = ParseWithViews("@recent", nil, views)
+	assert.NoError(t, err)
+	assert.Equal(t, OrderByEdit, query.OrderBy)
+	assert.Equal(t, OrderDescending, query.OrderDirection)
+
+	query, err = ParseWithViews("@recent sort:id", nil, views)
+	assert.NoError(t, err)
+	assert.Equal(t, OrderById, query.OrderBy)
+	assert.Equal(t, OrderAscending, query.OrderDirection)
+
+	for _, input := range []string{"@unknown", "@nested", "@broken", "@triage @"} {
+		t.Run(input, func(t *testing.T) {
+			query, err := ParseWithViews(input, nil, views)
+			assert.Error(t, err)
+			assert.Nil(t, query)
+		})
+	}
+
+	query, err = Parse("@triage")
+	assert.Error(t, err)
+	assert.Nil(t, query)
+}
@@ -1,6 +1,10 @@
 package query
 
-import "github.com/daedaleanai/git-ticket/bug"
+import (
+	"reflect"
+
+	"github.com/daedaleanai/git-ticket/bug"
+)
 
 // Query is the intermediary representation of a Bug's query. It is either
 // produced by parsing a query string (ex: "status:open author:rene") or created
@@ -8,8 +12,13 @@ import "github.com/daedaleanai/git-ticket/bug"
 // for the specific domain of application.
 type Query struct {
 	Filters
+	// Expressions are the parts of the query combined with OR, NOT or grouped
+	// in parenthesis, they are ANDed with the Filters.
+	Expressions []Expression
 	OrderBy
 	OrderDirection
+	// OrderKey is the metadata key the bugs are sorted by with OrderByMetadata
+	OrderKey string
 }
 
 // NewQuery return an identity query with the default sorting (creation-desc).
@@ -29,9 +38,73 @@ type Filters struct {
 	Participant []string
 	Label       []string
 	Title       []string
+	Created     []TimeRange
+	Edited      []TimeRange
+	Search      []string
+	Metadata    []MetadataFilter
+	Checklist   []ChecklistFilter
+	Review      []string
+	Reviewer    []string
 	NoLabel     bool
 }
 
+// ChecklistFilter matches the bugs with a checklist in the given compound
+// State, the one of the given Label or any if it's empty
+type ChecklistFilter struct {
+	Label bug.Label
+	State bug.ChecklistState
+}
+
+// MetadataFilter matches the bugs with the metadata Key on any operation, of
+// the given Value if HasValue is set
+type MetadataFilter struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// Expression is a node of the boolean expression tree of a query: And, Or or Not
+type Expression interface {
+	isExpression()
+}
+
+// And matches the bugs matching its Filters and all its Operands. As for a
+// query without operators, the Filters match if any of the statuses, authors,
+// actors, assignees, participants, review statuses and reviewers match, and
+// all of the labels, titles, time ranges, search terms, metadata and
+// checklists.
+type And struct {
+	Filters
+	Operands []Expression
+}
+
+// Or matches the bugs matching any of its Operands
+type Or struct {
+	Operands []Expression
+}
+
+// Not matches the bugs not matching its Operand
+type Not struct {
+	Operand Expression
+}
+
+// isEmpty returns true if the And has neither filters nor operands
+func (a *And) isEmpty() bool {
+	return len(a.Operands) == 0 && reflect.DeepEqual(a.Filters, Filters{})
+}
+
+// simplify returns the only operand of an And without filters
+func (a *And) simplify() Expression {
+	if len(a.Operands) == 1 && reflect.DeepEqual(a.Filters, Filters{}) {
+		return a.Operands[0]
+	}
+	return a
+}
+
+func (*And) isExpression() {}
+func (*Or) isExpression()  {}
+func (*Not) isExpression() {}
+
 type OrderBy int
 
 const (
@@ -39,6 +112,7 @@ const (
 	OrderById
 	OrderByCreation
 	OrderByEdit
+	OrderByMetadata
 )
 
 type OrderDirection int
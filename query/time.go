@@ -0,0 +1,189 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// now is the reference of the relative times, replaced in the tests
+var now = time.Now
+
+// TimeRange matches the times from After, included, to Before, excluded. A
+// zero bound leaves the range open on that side.
+type TimeRange struct {
+	After  time.Time
+	Before time.Time
+}
+
+// Match returns true if the time is within the range
+func (r TimeRange) Match(t time.Time) bool {
+	if !r.After.IsZero() && t.Before(r.After) {
+		return false
+	}
+	if !r.Before.IsZero() && !t.Before(r.Before) {
+		return false
+	}
+	return true
+}
+
+// The layouts of the absolute times, the period they cover being the last
+// element given: a year, a month, a day, a minute or a second
+var timeLayouts = []struct {
+	layout string
+	years  int
+	months int
+	days   int
+	period time.Duration
+}{
+	{layout: "2006", years: 1},
+	{layout: "2006-01", months: 1},
+	{layout: "2006-01-02", days: 1},
+	{layout: "2006-01-02T15:04", period: time.Minute},
+	{layout: "2006-01-02T15:04:05", period: time.Second},
+}
+
+// The units of the relative times
+var timeUnits = map[byte]time.Duration{
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// timeValue is a time given in a query, either an absolute period [start, end)
+// or the point in time a duration ago
+type timeValue struct {
+	start    time.Time
+	end      time.Time
+	relative bool
+}
+
+// parseTimeValue parses an absolute date (2026, 2026-03, 2026-03-15,
+// 2026-03-15T08:00) in the local time zone, or a duration ago (12h, 7d, 2w)
+func parseTimeValue(value string) (timeValue, error) {
+	if len(value) >= 2 {
+		if unit, ok := timeUnits[value[len(value)-1]]; ok {
+			if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+				t := now().Add(-time.Duration(n) * unit)
+				return timeValue{start: t, end: t, relative: true}, nil
+			}
+		}
+	}
+
+	for _, l := range timeLayouts {
+		start, err := time.ParseInLocation(l.layout, value, time.Local)
+		if err != nil {
+			continue
+		}
+		end := start.AddDate(l.years, l.months, l.days).Add(l.period)
+		return timeValue{start: start, end: end}, nil
+	}
+
+	return timeValue{}, fmt.Errorf("invalid time \"%s\", expected a date like 2026-03-15 or a duration like 7d", value)
+}
+
+// ParseTimeAfter returns the range of the times after the given one: after
+// the whole period of an absolute date, or more recent than a duration ago
+func ParseTimeAfter(value string) (TimeRange, error) {
+	v, err := parseTimeValue(value)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	return TimeRange{After: v.end}, nil
+}
+
+// ParseTimeBefore returns the range of the times before the given one: before
+// the start of an absolute date, or older than a duration ago
+func ParseTimeBefore(value string) (TimeRange, error) {
+	v, err := parseTimeValue(value)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	return TimeRange{Before: v.start}, nil
+}
+
+// parseTimeRange parses the value of a time qualifier:
+//
+//	2026-03              within the period
+//	>2026-03, >=2026-03  after the period, or from its start
+//	<2026-03, <=2026-03  before the period, or until its end
+//	2026-03..2026-06     from the start of the earliest period to the end of
+//	                     the latest, either bound can be omitted
+//
+// Durations are ages: 7d and <7d match the last 7 days, >30d what is older
+// than 30 days.
+func parseTimeRange(value string) (TimeRange, error) {
+	if i := strings.Index(value, ".."); i >= 0 {
+		from, to := value[:i], value[i+2:]
+		if from == "" && to == "" {
+			return TimeRange{}, fmt.Errorf("invalid time range \"%s\"", value)
+		}
+
+		var first, last *timeValue
+		if from != "" {
+			v, err := parseTimeValue(from)
+			if err != nil {
+				return TimeRange{}, err
+			}
+			first = &v
+		}
+		if to != "" {
+			v, err := parseTimeValue(to)
+			if err != nil {
+				return TimeRange{}, err
+			}
+			last = &v
+		}
+
+		// The durations count backwards, 30d..7d and 7d..30d are the same range
+		if first != nil && last != nil && last.start.Before(first.start) {
+			first, last = last, first
+		}
+
+		var r TimeRange
+		if first != nil {
+			r.After = first.start
+		}
+		if last != nil {
+			r.Before = last.end
+		}
+		return r, nil
+	}
+
+	var op string
+	for _, prefix := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, prefix) {
+			op = prefix
+			value = value[len(prefix):]
+			break
+		}
+	}
+
+	v, err := parseTimeValue(value)
+	if err != nil {
+		return TimeRange{}, err
+	}
+
+	if v.relative {
+		switch op {
+		case ">", ">=":
+			return TimeRange{Before: v.start}, nil
+		default:
+			return TimeRange{After: v.start}, nil
+		}
+	}
+
+	switch op {
+	case ">":
+		return TimeRange{After: v.end}, nil
+	case ">=":
+		return TimeRange{After: v.start}, nil
+	case "<":
+		return TimeRange{Before: v.start}, nil
+	case "<=":
+		return TimeRange{Before: v.end}, nil
+	default:
+		return TimeRange{After: v.start, Before: v.end}, nil
+	}
+}
@@ -0,0 +1,106 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	reference := time.Date(2026, 6, 15, 12, 0, 0, 0, time.Local)
+	now = func() time.Time { return reference }
+	defer func() { now = time.Now }()
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	}
+	daysAgo := func(n int) time.Time {
+		return reference.Add(-time.Duration(n) * 24 * time.Hour)
+	}
+
+	var tests = []struct {
+		input  string
+		output TimeRange
+	}{
+		{"2026-01-01", TimeRange{After: date(2026, 1, 1), Before: date(2026, 1, 2)}},
+		{"2026-03", TimeRange{After: date(2026, 3, 1), Before: date(2026, 4, 1)}},
+		{"2026", TimeRange{After: date(2026, 1, 1), Before: date(2027, 1, 1)}},
+		{"2026-03-15T08:30", TimeRange{
+			After:  time.Date(2026, 3, 15, 8, 30, 0, 0, time.Local),
+			Before: time.Date(2026, 3, 15, 8, 31, 0, 0, time.Local),
+		}},
+		{">2026-01-01", TimeRange{After: date(2026, 1, 2)}},
+		{">=2026-01-01", TimeRange{After: date(2026, 1, 1)}},
+		{"<2026-01", TimeRange{Before: date(2026, 1, 1)}},
+		{"<=2026-01", TimeRange{Before: date(2026, 2, 1)}},
+		{"2026-03..2026-06", TimeRange{After: date(2026, 3, 1), Before: date(2026, 7, 1)}},
+		{"2026-03..", TimeRange{After: date(2026, 3, 1)}},
+		{"..2026-06", TimeRange{Before: date(2026, 7, 1)}},
+
+		{"7d", TimeRange{After: daysAgo(7)}},
+		{"<7d", TimeRange{After: daysAgo(7)}},
+		{">30d", TimeRange{Before: daysAgo(30)}},
+		{"<12h", TimeRange{After: reference.Add(-12 * time.Hour)}},
+		{">2w", TimeRange{Before: daysAgo(14)}},
+		{"30d..7d", TimeRange{After: daysAgo(30), Before: daysAgo(7)}},
+		{"7d..30d", TimeRange{After: daysAgo(30), Before: daysAgo(7)}},
+		{"2026-06..7d", TimeRange{After: date(2026, 6, 1), Before: daysAgo(7)}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			r, err := parseTimeRange(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, r)
+		})
+	}
+
+	for _, input := range []string{"", "..", "yesterday", "7", "d", "-7d", "7m", "2026-13", ">", "2026..soon"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := parseTimeRange(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseTimeBounds(t *testing.T) {
+	reference := time.Date(2026, 6, 15, 12, 0, 0, 0, time.Local)
+	now = func() time.Time { return reference }
+	defer func() { now = time.Now }()
+
+	r, err := ParseTimeAfter("2026-01")
+	require.NoError(t, err)
+	assert.Equal(t, TimeRange{After: time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local)}, r)
+
+	r, err = ParseTimeAfter("7d")
+	require.NoError(t, err)
+	assert.Equal(t, TimeRange{After: reference.Add(-7 * 24 * time.Hour)}, r)
+
+	r, err = ParseTimeBefore("2026-01")
+	require.NoError(t, err)
+	assert.Equal(t, TimeRange{Before: time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)}, r)
+
+	r, err = ParseTimeBefore("30d")
+	require.NoError(t, err)
+	assert.Equal(t, TimeRange{Before: reference.Add(-30 * 24 * time.Hour)}, r)
+
+	_, err = ParseTimeBefore("last week")
+	assert.Error(t, err)
+}
+
+func TestTimeRange_Match(t *testing.T) {
+	after := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	r := TimeRange{After: after, Before: before}
+	assert.True(t, r.Match(after))
+	assert.True(t, r.Match(before.Add(-time.Second)))
+	assert.False(t, r.Match(before))
+	assert.False(t, r.Match(after.Add(-time.Second)))
+
+	assert.True(t, TimeRange{After: after}.Match(before.AddDate(10, 0, 0)))
+	assert.True(t, TimeRange{Before: before}.Match(time.Unix(0, 0)))
+	assert.True(t, TimeRange{}.Match(time.Now()))
+}
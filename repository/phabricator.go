@@ -67,11 +67,21 @@ func getPhabUrl() (string, error) {
 	return config.PhabUrl, nil
 }
 
-// GetPhabClient returns the connection ready to be queried. Must be called
-// within a git repo which has a .arconfig file containing the phabricator.uri
-// field and the Phabricator conduit API token set in the git config
-// daedalean.taskmgr-api-token.
-func GetPhabClient() (*gonduit.Conn, error) {
+// GetPhabClient returns the connection to the Phabricator server at the given
+// url, ready to be queried with the given Conduit API token.
+func GetPhabClient(phabUrl, apiToken string) (*gonduit.Conn, error) {
+	if phabUrl == "" {
+		return nil, errors.New("no Phabricator url given")
+	}
+
+	return gonduit.Dial(phabUrl, &core.ClientOptions{APIToken: apiToken})
+}
+
+// GetConfiguredPhabClient returns the connection ready to be queried. Must be
+// called within a git repo which has a .arconfig file containing the
+// phabricator.uri field and the Phabricator conduit API token set in the git
+// config daedalean.taskmgr-api-token.
+func GetConfiguredPhabClient() (*gonduit.Conn, error) {
 	apiToken, err := getApiToken()
 	if err != nil {
 		return nil, err
@@ -82,5 +92,5 @@ func GetPhabClient() (*gonduit.Conn, error) {
 		return nil, err
 	}
 
-	return gonduit.Dial(phabUrl, &core.ClientOptions{APIToken: apiToken})
+	return GetPhabClient(phabUrl, apiToken)
 }
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thought-machine/gonduit"
+)
+
+// FakeConduitToken is the Conduit API token accepted by the fake Conduit server
+const FakeConduitToken = "api-faketoken"
+
+// FakeConduit is a stand-in Phabricator server answering the Conduit API
+// methods used by git-ticket from fixture files:
+//
+//	transaction.search/<object>.json     the transactions of an object, served
+//	                                     newest first and paged as Phabricator does
+//	differential.revision.search.json    the revisions
+//	differential.diff.search.json        the diffs
+//	user.search.json                     the users
+//
+// The search fixtures hold the list of results, filtered by the ids, phids,
+// usernames and modifiedStart constraints of the requests.
+type FakeConduit struct {
+	*httptest.Server
+
+	// PageSize caps the number of transactions returned per request, to
+	// exercise the paging of the clients
+	PageSize int
+
+	fixtures string
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+// ConduitFixtures returns the directory of the fixtures of the repository,
+// usable with NewFakeConduit
+func ConduitFixtures() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "testdata", "conduit")
+}
+
+// NewFakeConduit starts a fake Conduit server serving the fixtures of the given
+// directory. It must be closed once done.
+func NewFakeConduit(fixtures string) *FakeConduit {
+	fake := &FakeConduit{
+		fixtures: fixtures,
+		requests: make(map[string]int),
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	return fake
+}
+
+// Requests returns the number of requests received for a Conduit method
+func (f *FakeConduit) Requests(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[method]
+}
+
+// Client returns a connection to the fake server
+func (f *FakeConduit) Client(t testing.TB) *gonduit.Conn {
+	conn, err := GetPhabClient(f.URL, FakeConduitToken)
+	require.NoError(t, err)
+	return conn
+}
+
+type fakeConduitParams struct {
+	Conduit struct {
+		Token string `json:"token"`
+	} `json:"__conduit__"`
+	ObjectID    string                 `json:"objectIdentifier"`
+	Constraints map[string]interface{} `json:"constraints"`
+	Before      string                 `json:"before"`
+	After       string                 `json:"after"`
+	Limit       int                    `json:"limit"`
+}
+
+func (f *FakeConduit) serve(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+
+	f.mu.Lock()
+	f.requests[method]++
+	f.mu.Unlock()
+
+	var params fakeConduitParams
+	if err := json.Unmarshal([]byte(r.FormValue("params")), &params); err != nil {
+		fakeConduitError(w, "ERR-CONDUIT-CORE", err.Error())
+		return
+	}
+
+	if method == "conduit.getcapabilities" {
+		fakeConduitResult(w, map[string][]string{
+			"authentication": {"token"},
+			"input":          {"json", "urlencoded"},
+			"output":         {"json"},
+		})
+		return
+	}
+
+	if params.Conduit.Token != FakeConduitToken {
+		fakeConduitError(w, "ERR-INVALID-AUTH", "API token is invalid")
+		return
+	}
+
+	var result interface{}
+	var err error
+
+	switch method {
+	case "transaction.search":
+		result, err = f.transactionSearch(params)
+	case "differential.revision.search", "differential.diff.search", "user.search":
+		result, err = f.search(method, params)
+	default:
+		fakeConduitError(w, "ERR-CONDUIT-CALL", fmt.Sprintf("method %s not found", method))
+		return
+	}
+
+	if err != nil {
+		fakeConduitError(w, "ERR-CONDUIT-CORE", err.Error())
+		return
+	}
+	fakeConduitResult(w, result)
+}
+
+func (f *FakeConduit) readFixture(name string, v interface{}) error {
+	data, err := ioutil.ReadFile(filepath.Join(f.fixtures, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// transactionSearch serves the transactions of an object newest first. With a
+// before cursor the page of the oldest transactions newer than it is returned,
+// with an after cursor the page of the newest transactions older than it.
+func (f *FakeConduit) transactionSearch(params fakeConduitParams) (interface{}, error) {
+	var all []map[string]interface{}
+	if err := f.readFixture(filepath.Join("transaction.search", params.ObjectID+".json"), &all); err != nil {
+		return nil, err
+	}
+	if all == nil {
+		return nil, fmt.Errorf("object %s not found", params.ObjectID)
+	}
+
+	id := func(t map[string]interface{}) int {
+		return int(t["id"].(float64))
+	}
+	sort.Slice(all, func(i, j int) bool { return id(all[i]) > id(all[j]) })
+
+	limit := params.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	if f.PageSize > 0 && f.PageSize < limit {
+		limit = f.PageSize
+	}
+
+	var before, after interface{}
+	var page []map[string]interface{}
+
+	switch {
+	case params.Before != "":
+		cursor, err := strconv.Atoi(params.Before)
+		if err != nil {
+			return nil, err
+		}
+		var newer []map[string]interface{}
+		for _, t := range all {
+			if id(t) > cursor {
+				newer = append(newer, t)
+			}
+		}
+		if len(newer) > limit {
+			page = newer[len(newer)-limit:]
+			before = strconv.Itoa(id(page[0]))
+		} else {
+			page = newer
+		}
+		if len(page) > 0 {
+			after = strconv.Itoa(id(page[len(page)-1]))
+		}
+
+	default:
+		older := all
+		if params.After != "" {
+			cursor, err := strconv.Atoi(params.After)
+			if err != nil {
+				return nil, err
+			}
+			older = nil
+			for _, t := range all {
+				if id(t) < cursor {
+					older = append(older, t)
+				}
+			}
+			if len(older) > 0 {
+				before = strconv.Itoa(id(older[0]))
+			}
+		}
+		if len(older) > limit {
+			page = older[:limit]
+			after = strconv.Itoa(id(page[len(page)-1]))
+		} else {
+			page = older
+		}
+	}
+
+	if page == nil {
+		page = []map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"data": page,
+		"cursor": map[string]interface{}{
+			"limit":  limit,
+			"before": before,
+			"after":  after,
+		},
+	}, nil
+}
+
+// search serves the results of a fixture matching the constraints of the request
+func (f *FakeConduit) search(method string, params fakeConduitParams) (interface{}, error) {
+	var all []map[string]interface{}
+	if err := f.readFixture(method+".json", &all); err != nil {
+		return nil, err
+	}
+
+	data := []map[string]interface{}{}
+	for _, item := range all {
+		if fakeConduitMatch(item, params.Constraints) {
+			data = append(data, item)
+		}
+	}
+
+	return map[string]interface{}{
+		"data": data,
+		"cursor": map[string]interface{}{
+			"limit":  len(data),
+			"before": nil,
+			"after":  nil,
+		},
+	}, nil
+}
+
+func fakeConduitMatch(item map[string]interface{}, constraints map[string]interface{}) bool {
+	for key, values := range constraints {
+		var value interface{}
+		switch key {
+		case "ids":
+			value = item["id"]
+		case "phids":
+			value = item["phid"]
+		case "usernames":
+			fields, _ := item["fields"].(map[string]interface{})
+			value = fields["username"]
+		case "modifiedStart":
+			fields, _ := item["fields"].(map[string]interface{})
+			modified, _ := fields["dateModified"].(float64)
+			start, _ := values.(float64)
+			if modified < start {
+				return false
+			}
+			continue
+		default:
+			continue
+		}
+
+		list, _ := values.([]interface{})
+		found := false
+		for _, v := range list {
+			if fmt.Sprint(v) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func fakeConduitResult(w http.ResponseWriter, result interface{}) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"result":     result,
+		"error_code": nil,
+		"error_info": nil,
+	})
+}
+
+func fakeConduitError(w http.ResponseWriter, code, info string) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"result":     nil,
+		"error_code": code,
+		"error_info": info,
+	})
+}
@@ -26,6 +26,7 @@ const defaultQuery = ""
 var bugTableHelp = helpBar{
 	{"q", "Quit"},
 	{"s", "Search"},
+	{"v", "Views"},
 	{"←↓↑→,hjkl", "Navigation"},
 	{"↵", "Open bug"},
 	{"n", "New bug"},
@@ -44,7 +45,7 @@ type bugTable struct {
 }
 
 func newBugTable(c *cache.RepoCache) *bugTable {
-	q, err := query.Parse(defaultQuery)
+	q, err := query.ParseWithConfigs(defaultQuery, c.Configs())
 	if err != nil {
 		panic(err)
 	}
@@ -217,6 +218,12 @@ func (bt *bugTable) keybindings(g *gocui.Gui) error {
 		return err
 	}
 
+	// Saved views
+	if err := g.SetKeybinding(bugTableView, 'v', gocui.ModNone,
+		bt.selectView); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -546,3 +553,19 @@ func (bt *bugTable) push(g *gocui.Gui, v *gocui.View) error {
 func (bt *bugTable) changeQuery(g *gocui.Gui, v *gocui.View) error {
 	return editQueryWithEditor(bt)
 }
+
+func (bt *bugTable) selectView(g *gocui.Gui, v *gocui.View) error {
+	if err := ui.viewSelect.Refresh(); err != nil {
+		ui.msgPopup.Activate(msgPopupErrorTitle, err.Error())
+		return nil
+	}
+	return ui.activateWindow(ui.viewSelect)
+}
+
+// setQuery replaces the query of the table, going back to its first page
+func (bt *bugTable) setQuery(queryStr string, q *query.Query) {
+	bt.queryStr = queryStr
+	bt.query = q
+	bt.pageCursor = 0
+	bt.selectCursor = 0
+}
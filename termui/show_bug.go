@@ -22,6 +22,9 @@ const showBugHeaderView = "showBugHeaderView"
 
 const timeLayout = "Jan 2 2006"
 
+// maxStatusKeys is the number of next states which can be selected with the digit keys
+const maxStatusKeys = 9
+
 var showBugHelp = helpBar{
 	{"q", "Save and return"},
 	{"←↓↑→,hjkl", "Navigation"},
@@ -109,14 +112,17 @@ func (sb *showBug) layout(g *gocui.Gui) error {
 
 	currentBugHelp := showBugHelp
 
-	validStates, err := sb.bug.Snapshot().NextStates()
-	for _, vs := range validStates {
+	validStates, err := sb.bug.Snapshot().NextStates(sb.cache.Configs())
+	for i, vs := range validStates {
+		if i >= maxStatusKeys {
+			break
+		}
 		currentBugHelp = append(currentBugHelp,
 			struct {
 				keys string
 				text string
 			}{
-				keys: strconv.Itoa(int(vs)),
+				keys: strconv.Itoa(i + 1),
 				text: vs.Action()})
 	}
 
@@ -191,13 +197,17 @@ func (sb *showBug) keybindings(g *gocui.Gui) error {
 		return err
 	}
 
-	// Set Status
-	for s := bug.FirstStatus; s <= bug.LastStatus; s++ {
-		status := s
-		key := '0' + rune(status)
+	// Set Status, the digit keys select one of the next possible states
+	for i := 0; i < maxStatusKeys; i++ {
+		index := i
+		key := '1' + rune(i)
 
 		callback := func(g *gocui.Gui, v *gocui.View) error {
-			_, _ = sb.bug.SetStatus(status)
+			validStates, err := sb.bug.Snapshot().NextStates(sb.cache.Configs())
+			if err != nil || index >= len(validStates) {
+				return nil
+			}
+			_, _ = sb.bug.SetStatus(validStates[index])
 			// don't report error because that will drop us out of the termui
 			return nil
 		}
@@ -670,7 +680,7 @@ func (sb *showBug) review(g *gocui.Gui, v *gocui.View) error {
 		return err
 	}
 
-	ticketChecklists, err := sb.bug.Snapshot().GetUserChecklists(id.Id())
+	ticketChecklists, err := sb.bug.Snapshot().GetUserChecklists(sb.cache.Configs(), id.Id())
 	if err != nil {
 		return err
 	}
@@ -11,7 +11,6 @@ import (
 	"github.com/daedaleanai/git-ticket/cache"
 	"github.com/daedaleanai/git-ticket/entity"
 	"github.com/daedaleanai/git-ticket/input"
-	"github.com/daedaleanai/git-ticket/query"
 )
 
 var errTerminateMainloop = errors.New("terminate gocui mainloop")
@@ -26,6 +25,7 @@ type termUI struct {
 	bugTable    *bugTable
 	showBug     *showBug
 	labelSelect *labelSelect
+	viewSelect  *viewSelect
 	msgPopup    *msgPopup
 	inputPopup  *inputPopup
 }
@@ -56,6 +56,7 @@ func Run(cache *cache.RepoCache) error {
 		bugTable:    newBugTable(cache),
 		showBug:     newShowBug(cache),
 		labelSelect: newLabelSelect(),
+		viewSelect:  newViewSelect(cache),
 		msgPopup:    newMsgPopup(),
 		inputPopup:  newInputPopup(),
 	}
@@ -159,6 +160,10 @@ func keybindings(g *gocui.Gui) error {
 		return err
 	}
 
+	if err := ui.viewSelect.keybindings(g); err != nil {
+		return err
+	}
+
 	if err := ui.msgPopup.keybindings(g); err != nil {
 		return err
 	}
@@ -296,11 +301,12 @@ func reviewWithEditor(bug *cache.BugCache, checklist bug.Checklist) error {
 	ui.g = nil
 
 	clChange, err := input.ChecklistEditorInput(ui.cache, checklist)
-	if err != nil {
-		return err
-	}
 
-	if !clChange {
+	// An invalid checklist, such as a missing justification, is reported so
+	// the reviewer can fix it
+	if err != nil {
+		ui.msgPopup.Activate(msgPopupErrorTitle, err.Error())
+	} else if !clChange {
 		ui.msgPopup.Activate("", "Checklists unchanged")
 	} else {
 		_, err := bug.SetChecklist(checklist)
@@ -373,7 +379,7 @@ func editQueryWithEditor(bt *bugTable) error {
 
 	bt.queryStr = queryStr
 
-	q, err := query.Parse(queryStr)
+	q, err := bt.repo.ParseQuery(queryStr)
 
 	if err != nil {
 		ui.msgPopup.Activate(msgPopupErrorTitle, err.Error())
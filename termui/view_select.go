@@ -0,0 +1,190 @@
+package termui
+
+import (
+	"fmt"
+
+	text "github.com/MichaelMure/go-term-text"
+	"github.com/awesome-gocui/gocui"
+
+	"github.com/daedaleanai/git-ticket/cache"
+	"github.com/daedaleanai/git-ticket/util/colors"
+)
+
+const viewSelectView = "viewSelectView"
+const viewSelectInstructionsView = "viewSelectInstructionsView"
+
+var viewSelectHelp = helpBar{
+	{"q", "Close"},
+	{"↓↑,jk", "Nav"},
+	{"↵", "Apply view"},
+}
+
+// viewSelect lists the saved views to replace the query of the bug table
+type viewSelect struct {
+	cache    *cache.RepoCache
+	views    []cache.View
+	selected int
+	scroll   int
+}
+
+func newViewSelect(cache *cache.RepoCache) *viewSelect {
+	return &viewSelect{cache: cache}
+}
+
+// Refresh reads the saved views again, the shared views hidden by a local one
+// being skipped
+func (vs *viewSelect) Refresh() error {
+	views, err := vs.cache.Views()
+	if err != nil {
+		return err
+	}
+
+	vs.views = nil
+	for i, v := range views {
+		if i > 0 && views[i-1].Name == v.Name {
+			continue
+		}
+		vs.views = append(vs.views, v)
+	}
+
+	vs.selected = 0
+	vs.scroll = 0
+
+	return nil
+}
+
+func (vs *viewSelect) keybindings(g *gocui.Gui) error {
+	// Abort
+	if err := g.SetKeybinding(viewSelectView, gocui.KeyEsc, gocui.ModNone, vs.abort); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(viewSelectView, 'q', gocui.ModNone, vs.abort); err != nil {
+		return err
+	}
+	// Up
+	if err := g.SetKeybinding(viewSelectView, gocui.KeyArrowUp, gocui.ModNone, vs.selectPrevious); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(viewSelectView, 'k', gocui.ModNone, vs.selectPrevious); err != nil {
+		return err
+	}
+	// Down
+	if err := g.SetKeybinding(viewSelectView, gocui.KeyArrowDown, gocui.ModNone, vs.selectNext); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding(viewSelectView, 'j', gocui.ModNone, vs.selectNext); err != nil {
+		return err
+	}
+	// Apply
+	if err := g.SetKeybinding(viewSelectView, gocui.KeyEnter, gocui.ModNone, vs.apply); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (vs *viewSelect) layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	v, err := g.SetView(viewSelectView, -1, -1, maxX, maxY-2, 0)
+	if err != nil {
+		if !gocui.IsUnknownView(err) {
+			return err
+		}
+
+		v.Frame = false
+		v.SelBgColor = gocui.ColorWhite
+		v.SelFgColor = gocui.ColorBlack
+	}
+
+	width, height := v.Size()
+
+	// Keep the selected view visible
+	if vs.selected < vs.scroll {
+		vs.scroll = vs.selected
+	}
+	if height > 0 && vs.selected >= vs.scroll+height {
+		vs.scroll = vs.selected - height + 1
+	}
+
+	nameWidth := 5
+	for _, view := range vs.views {
+		nameWidth = maxInt(nameWidth, text.Len(view.Name)+1)
+	}
+
+	v.Clear()
+	if len(vs.views) == 0 {
+		_, _ = fmt.Fprint(v, "No saved views, see \"git ticket view save\"")
+	}
+	for _, view := range vs.views[minInt(vs.scroll, len(vs.views)):] {
+		scope := "local"
+		if view.Shared {
+			scope = "shared"
+		}
+		name := text.LeftPadMaxLine("@"+view.Name, nameWidth, 0)
+		q := text.LeftPadMaxLine(view.Query, maxInt(width-nameWidth-10, 0), 0)
+		_, _ = fmt.Fprintf(v, "%s %s %s\n", colors.Cyan(name), colors.Yellow(fmt.Sprintf("%-7s", scope)), q)
+	}
+	if len(vs.views) > 0 {
+		_ = v.SetHighlight(vs.selected-vs.scroll, true)
+	}
+
+	v, err = g.SetView(viewSelectInstructionsView, -1, maxY-2, maxX, maxY, 0)
+	if err != nil {
+		if !gocui.IsUnknownView(err) {
+			return err
+		}
+		v.Frame = false
+		v.FgColor = gocui.ColorWhite
+	}
+	v.Clear()
+	_, _ = fmt.Fprint(v, viewSelectHelp.Render(maxX))
+
+	if _, err := g.SetCurrentView(viewSelectView); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (vs *viewSelect) disable(g *gocui.Gui) error {
+	if err := g.DeleteView(viewSelectView); err != nil && !gocui.IsUnknownView(err) {
+		return err
+	}
+	if err := g.DeleteView(viewSelectInstructionsView); err != nil && !gocui.IsUnknownView(err) {
+		return err
+	}
+	return nil
+}
+
+func (vs *viewSelect) selectPrevious(g *gocui.Gui, v *gocui.View) error {
+	vs.selected = maxInt(0, vs.selected-1)
+	return nil
+}
+
+func (vs *viewSelect) selectNext(g *gocui.Gui, v *gocui.View) error {
+	vs.selected = maxInt(0, minInt(len(vs.views)-1, vs.selected+1))
+	return nil
+}
+
+func (vs *viewSelect) abort(g *gocui.Gui, v *gocui.View) error {
+	return ui.activateWindow(ui.bugTable)
+}
+
+// apply replaces the query of the bug table with the selected view, which can
+// then be refined with the query editor
+func (vs *viewSelect) apply(g *gocui.Gui, v *gocui.View) error {
+	if len(vs.views) == 0 {
+		return nil
+	}
+
+	queryStr := "@" + vs.views[vs.selected].Name
+
+	q, err := vs.cache.ParseQuery(queryStr)
+	if err != nil {
+		ui.msgPopup.Activate(msgPopupErrorTitle, err.Error())
+		return nil
+	}
+
+	ui.bugTable.setQuery(queryStr, q)
+
+	return ui.activateWindow(ui.bugTable)
+}